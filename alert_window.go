@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"github.com/AllenDang/giu"
+	"image/color"
+)
+
+const (
+	AlertWindowWidth  = 700
+	AlertWindowHeight = 300
+)
+
+var (
+	AlertFiringColor   = color.RGBA{R: 110, G: 30, B: 30, A: 255}
+	AlertResolvedColor = color.RGBA{R: 30, G: 70, B: 30, A: 255}
+)
+
+// unacknowledgedAlerts returns the number of alerts not acknowledged yet
+func (a *App) unacknowledgedAlerts() int {
+	count := 0
+	for _, alert := range a.alerts {
+		if !alert.Acknowledged {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *App) acknowledgeAllAlerts() {
+	a.acknowledgeAlert(0)
+	for idx := range a.alerts {
+		a.alerts[idx].Acknowledged = true
+	}
+}
+
+// renderAlertWindow builds the window listing fired alerts, unacknowledged alerts are highlighted
+func (a *App) renderAlertWindow() {
+	if !a.alertsOpen {
+		return
+	}
+
+	rows := []*giu.TableRowWidget{
+		giu.TableRow(
+			giu.Label("Fired"),
+			giu.Label("Resolved"),
+			giu.Label("Container"),
+			giu.Label("Rule"),
+			giu.Label("Message"),
+			giu.Label(""),
+		).Flags(giu.TableRowFlagsHeaders),
+	}
+	for idx, alert := range a.alerts {
+		resolved := "firing"
+		if !alert.IsFiring() {
+			resolved = alert.ResolvedAt.Local().Format("15:04:05")
+		}
+		name := alert.ContainerName
+		if len(a.hostData) > 1 {
+			name = fmt.Sprintf("%s (%s)", name, alert.Host)
+		}
+		row := giu.TableRow(
+			giu.Label(alert.FiredAt.Local().Format("2006-01-02 15:04:05")),
+			giu.Label(resolved),
+			giu.Label(name),
+			giu.Label(alert.Rule),
+			giu.Label(alert.Message),
+			giu.Condition(alert.Acknowledged,
+				giu.Layout{giu.Label("ack")},
+				giu.Layout{
+					giu.Button(fmt.Sprintf("Ack##alert%d", alert.ID)).OnClick(func() {
+						a.acknowledgeAlert(alert.ID)
+						a.alerts[idx].Acknowledged = true
+					}),
+				},
+			),
+		)
+		if !alert.Acknowledged && alert.IsFiring() {
+			row.BgColor(AlertFiringColor)
+		} else if !alert.Acknowledged {
+			row.BgColor(AlertResolvedColor)
+		}
+		rows = append(rows, row)
+	}
+
+	giu.Window("Alerts").IsOpen(&a.alertsOpen).Size(AlertWindowWidth, AlertWindowHeight).Layout(
+		giu.Row(
+			giu.Button("Acknowledge all").OnClick(a.acknowledgeAllAlerts),
+			giu.Label(fmt.Sprintf("%d alerts, %d unacknowledged", len(a.alerts), a.unacknowledgedAlerts())),
+		),
+		giu.Condition(len(a.alerts) == 0,
+			giu.Layout{giu.Label("No alerts fired yet, rules are configured by \"alert_rules\" of the config file")},
+			giu.Layout{giu.Table().ID("alerts").Freeze(0, 1).Rows(rows...)},
+		),
+	)
+}
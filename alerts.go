@@ -0,0 +1,452 @@
+package main
+
+import (
+	"fmt"
+	"github.com/inhies/go-bytesize"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxAlerts is the number of alerts kept, the oldest resolved alerts are dropped first
+const MaxAlerts = 500
+
+type alertRuleKind int
+
+const (
+	alertThreshold  alertRuleKind = iota // metric compared to a threshold, e.g. "cpu > 150% for 2m"
+	alertTransition                      // state or health changed to given value, e.g. "health becomes unhealthy"
+	alertRestarts                        // number of restarts within a time window, e.g. "restarted 3 times in 10m"
+)
+
+// alertMetrics are the metrics of a container usable in threshold rules
+var alertMetrics = map[string]func(data ContainerData) float64{
+	"cpu":            func(data ContainerData) float64 { return data.CpuPercent },
+	"cpu_throttled":  func(data ContainerData) float64 { return data.CpuThrottledPercent },
+	"memory":         func(data ContainerData) float64 { return float64(data.Memory) },
+	"memory_percent": func(data ContainerData) float64 { return data.MemoryPercent },
+	"pids":           func(data ContainerData) float64 { return float64(data.PIDs) },
+}
+
+// AlertRule is a parsed rule of the alert engine.
+//
+// Supported rules are
+//
+//	<metric> <op> <value> [for <duration>] [hysteresis <value>]
+//	health becomes healthy|unhealthy|unknown
+//	state becomes <state>
+//	restarted <n> times in <duration>
+//
+// with metric one of cpu, cpu_throttled, memory, memory_percent or pids and op one of >, >=, < or <=.
+// Percent values may have a % suffix, memory values a unit like 512MB.
+// A firing threshold alert resolves when the value is better than the threshold by at least hysteresis.
+type AlertRule struct {
+	Text string
+
+	kind alertRuleKind
+
+	metric      string
+	op          string
+	threshold   float64
+	hysteresis  float64
+	forDuration time.Duration
+
+	field   string // "health" or "state"
+	becomes string
+
+	count  int
+	window time.Duration
+}
+
+// ParseAlertRule parses the text of an alert rule
+func ParseAlertRule(text string) (AlertRule, error) {
+	rule := AlertRule{Text: text}
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 {
+		return rule, fmt.Errorf("empty rule")
+	}
+
+	switch {
+	case len(fields) == 3 && (fields[0] == "health" || fields[0] == "state") && fields[1] == "becomes":
+		rule.kind = alertTransition
+		rule.field = fields[0]
+		rule.becomes = fields[2]
+		valid := []string{Healthy.String(), Unhealthy.String(), StartingHealth.String(), UnknownHealth.String()}
+		if rule.field == "state" {
+			valid = nil
+			for state := ContainerUnknownState; state <= ContainerPaused; state++ {
+				valid = append(valid, state.String())
+			}
+		}
+		if !slices.Contains(valid, rule.becomes) {
+			return rule, fmt.Errorf("%q: unknown %s %q, expected one of %s", text, rule.field, rule.becomes, strings.Join(valid, ", "))
+		}
+		return rule, nil
+
+	case fields[0] == "restarted":
+		if len(fields) != 5 || fields[2] != "times" || fields[3] != "in" {
+			return rule, fmt.Errorf("%q: expected \"restarted <n> times in <duration>\"", text)
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil || count < 1 {
+			return rule, fmt.Errorf("%q: invalid number of restarts %q", text, fields[1])
+		}
+		window, err := time.ParseDuration(fields[4])
+		if err != nil {
+			return rule, fmt.Errorf("%q: invalid duration %q", text, fields[4])
+		}
+		rule.kind = alertRestarts
+		rule.count = count
+		rule.window = window
+		return rule, nil
+	}
+
+	if _, ok := alertMetrics[fields[0]]; !ok {
+		metrics := make([]string, 0, len(alertMetrics))
+		for metric := range alertMetrics {
+			metrics = append(metrics, metric)
+		}
+		slices.Sort(metrics)
+		return rule, fmt.Errorf("%q: unknown metric %q, expected one of %s, health, state or restarted", text, fields[0], strings.Join(metrics, ", "))
+	}
+	if len(fields) < 3 {
+		return rule, fmt.Errorf("%q: expected \"<metric> <op> <value>\"", text)
+	}
+	rule.kind = alertThreshold
+	rule.metric = fields[0]
+	rule.op = fields[1]
+	if !slices.Contains([]string{">", ">=", "<", "<="}, rule.op) {
+		return rule, fmt.Errorf("%q: unknown operator %q, expected one of >, >=, < or <=", text, rule.op)
+	}
+	threshold, err := parseAlertValue(rule.metric, fields[2])
+	if err != nil {
+		return rule, fmt.Errorf("%q: %v", text, err)
+	}
+	rule.threshold = threshold
+
+	for rest := fields[3:]; len(rest) > 0; rest = rest[2:] {
+		if len(rest) < 2 {
+			return rule, fmt.Errorf("%q: missing value of %q", text, rest[0])
+		}
+		switch rest[0] {
+		case "for":
+			if rule.forDuration, err = time.ParseDuration(rest[1]); err != nil {
+				return rule, fmt.Errorf("%q: invalid duration %q", text, rest[1])
+			}
+		case "hysteresis":
+			if rule.hysteresis, err = parseAlertValue(rule.metric, rest[1]); err != nil {
+				return rule, fmt.Errorf("%q: %v", text, err)
+			}
+		default:
+			return rule, fmt.Errorf("%q: unexpected %q, expected \"for\" or \"hysteresis\"", text, rest[0])
+		}
+	}
+	return rule, nil
+}
+
+// parseAlertValue parses a threshold of given metric, memory accepts units, percentages a % suffix
+func parseAlertValue(metric string, value string) (float64, error) {
+	if metric == "memory" {
+		if size, err := bytesize.Parse(value); err == nil {
+			return float64(size), nil
+		}
+	}
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return number, nil
+}
+
+// matches returns true if the value violates the threshold of the rule
+func (r AlertRule) matches(value float64) bool {
+	switch r.op {
+	case ">":
+		return value > r.threshold
+	case ">=":
+		return value >= r.threshold
+	case "<":
+		return value < r.threshold
+	default:
+		return value <= r.threshold
+	}
+}
+
+// recovered returns true if the value is better than the threshold by at least the hysteresis
+func (r AlertRule) recovered(value float64) bool {
+	switch r.op {
+	case ">", ">=":
+		return value < r.threshold-r.hysteresis
+	default:
+		return value > r.threshold+r.hysteresis
+	}
+}
+
+// Alert is an alert fired by a rule for a container
+type Alert struct {
+	ID            int
+	Rule          string
+	ContainerID   string
+	ContainerName string
+	Host          string
+	Message       string
+	FiredAt       time.Time
+	ResolvedAt    time.Time // zero while the alert is firing
+	Acknowledged  bool
+}
+
+// IsFiring returns true if the alert is not resolved yet
+func (a Alert) IsFiring() bool {
+	return a.ResolvedAt.IsZero()
+}
+
+// alertKey identifies the state of a rule for a container
+type alertKey struct {
+	rule      string
+	container string
+}
+
+type alertState struct {
+	pendingSince time.Time // start of violating the threshold, zero if not violated
+	firing       *Alert
+}
+
+// AlertEngine evaluates alert rules against container data and keeps the list of fired alerts
+type AlertEngine struct {
+	mutex    sync.Mutex
+	rules    []AlertRule
+	states   map[alertKey]*alertState
+	previous map[string]ContainerData // container data of the last evaluation by container id
+	restarts map[string][]time.Time   // times the container got restarted by container id
+	alerts   []*Alert
+	nextID   int
+
+	onFired    []func(alert Alert)
+	onResolved []func(alert Alert)
+}
+
+func NewAlertEngine() *AlertEngine {
+	return &AlertEngine{
+		states:   make(map[alertKey]*alertState),
+		previous: make(map[string]ContainerData),
+		restarts: make(map[string][]time.Time),
+		nextID:   1,
+	}
+}
+
+// OnFired registers a function called for every fired alert
+func (e *AlertEngine) OnFired(fired func(alert Alert)) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.onFired = append(e.onFired, fired)
+}
+
+// OnResolved registers a function called for every resolved alert
+func (e *AlertEngine) OnResolved(resolved func(alert Alert)) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.onResolved = append(e.onResolved, resolved)
+}
+
+// SetRules replaces the rules, the state of unchanged rules is kept and alerts of removed rules get resolved
+func (e *AlertEngine) SetRules(rules []AlertRule) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.rules = rules
+	var resolved []Alert
+	for key, state := range e.states {
+		if slices.ContainsFunc(rules, func(rule AlertRule) bool { return rule.Text == key.rule }) {
+			continue
+		}
+		if state.firing != nil {
+			resolved = append(resolved, e.resolve(state, time.Now()))
+		}
+		delete(e.states, key)
+	}
+	e.notify(nil, resolved)
+}
+
+// Evaluate checks all rules against the current data of the containers
+func (e *AlertEngine) Evaluate(containers []ContainerData, now time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var fired, resolved []Alert
+	seen := make(map[string]bool, len(containers))
+	for _, data := range containers {
+		seen[data.ID] = true
+		previous, known := e.previous[data.ID]
+		if known && previous.Created != 0 && data.Created != previous.Created {
+			e.restarts[data.ID] = append(e.restarts[data.ID], now)
+		}
+		e.pruneRestarts(data.ID, now)
+
+		for _, rule := range e.rules {
+			key := alertKey{rule: rule.Text, container: data.ID}
+			state, ok := e.states[key]
+			if !ok {
+				state = &alertState{}
+				e.states[key] = state
+			}
+
+			violated, recovered, message := e.check(rule, data, previous, known, now)
+			if violated {
+				if state.pendingSince.IsZero() {
+					state.pendingSince = now
+				}
+				if state.firing == nil && now.Sub(state.pendingSince) >= rule.forDuration {
+					fired = append(fired, e.fire(state, rule, data, message, now))
+				}
+			} else {
+				state.pendingSince = time.Time{}
+			}
+			if recovered && state.firing != nil {
+				resolved = append(resolved, e.resolve(state, now))
+			}
+		}
+		e.previous[data.ID] = data
+	}
+
+	// resolve alerts of removed containers
+	for key, state := range e.states {
+		if seen[key.container] {
+			continue
+		}
+		if state.firing != nil {
+			resolved = append(resolved, e.resolve(state, now))
+		}
+		delete(e.states, key)
+	}
+	for id := range e.previous {
+		if !seen[id] {
+			delete(e.previous, id)
+			delete(e.restarts, id)
+		}
+	}
+
+	e.notify(fired, resolved)
+}
+
+// pruneRestarts forgets the restarts of the container that are outside of the largest window of all restart rules
+func (e *AlertEngine) pruneRestarts(id string, now time.Time) {
+	var window time.Duration
+	for _, rule := range e.rules {
+		if rule.kind == alertRestarts {
+			window = max(window, rule.window)
+		}
+	}
+	e.restarts[id] = slices.DeleteFunc(e.restarts[id], func(t time.Time) bool {
+		return now.Sub(t) > window
+	})
+}
+
+// check returns whether the rule is violated by the container, whether a firing alert is resolved and a message
+func (e *AlertEngine) check(rule AlertRule, data ContainerData, previous ContainerData, known bool, now time.Time) (bool, bool, string) {
+	switch rule.kind {
+	case alertTransition:
+		current, before := data.HealthStatus.String(), previous.HealthStatus.String()
+		if rule.field == "state" {
+			current, before = data.State.String(), previous.State.String()
+		}
+		becomes := known && current == rule.becomes && before != rule.becomes
+		return becomes, current != rule.becomes, fmt.Sprintf("%s became %s", rule.field, current)
+
+	case alertRestarts:
+		restarts := 0
+		for _, t := range e.restarts[data.ID] {
+			if now.Sub(t) <= rule.window {
+				restarts++
+			}
+		}
+		return restarts >= rule.count, restarts < rule.count,
+			fmt.Sprintf("restarted %d times in %s", restarts, rule.window)
+
+	default:
+		if data.State != ContainerRunning {
+			return false, true, ""
+		}
+		value := alertMetrics[rule.metric](data)
+		formatted := fmt.Sprintf("%0.1f", value)
+		if rule.metric == "memory" {
+			formatted = bytesize.New(value).String()
+		}
+		return rule.matches(value), rule.recovered(value), fmt.Sprintf("%s is %s", rule.metric, formatted)
+	}
+}
+
+func (e *AlertEngine) fire(state *alertState, rule AlertRule, data ContainerData, message string, now time.Time) Alert {
+	alert := &Alert{
+		ID:            e.nextID,
+		Rule:          rule.Text,
+		ContainerID:   data.ID,
+		ContainerName: data.AlternativeName,
+		Host:          data.Host,
+		Message:       message,
+		FiredAt:       now,
+	}
+	e.nextID++
+	state.firing = alert
+	e.alerts = append(e.alerts, alert)
+	if len(e.alerts) > MaxAlerts {
+		idx := slices.IndexFunc(e.alerts, func(a *Alert) bool { return !a.IsFiring() })
+		if idx < 0 {
+			idx = 0
+		}
+		e.alerts = slices.Delete(e.alerts, idx, idx+1)
+	}
+	fmt.Printf("Alert fired for container %s (%s): %s, %s\n", data.AlternativeName, data.ID, rule.Text, message)
+	return *alert
+}
+
+func (e *AlertEngine) resolve(state *alertState, now time.Time) Alert {
+	alert := state.firing
+	alert.ResolvedAt = now
+	state.firing = nil
+	fmt.Printf("Alert resolved for container %s (%s): %s\n", alert.ContainerName, alert.ContainerID, alert.Rule)
+	return *alert
+}
+
+// notify calls the registered functions in a separate goroutine to not block the evaluation
+func (e *AlertEngine) notify(fired []Alert, resolved []Alert) {
+	if len(fired) == 0 && len(resolved) == 0 {
+		return
+	}
+	onFired, onResolved := slices.Clone(e.onFired), slices.Clone(e.onResolved)
+	go func() {
+		for _, alert := range fired {
+			for _, f := range onFired {
+				f(alert)
+			}
+		}
+		for _, alert := range resolved {
+			for _, f := range onResolved {
+				f(alert)
+			}
+		}
+	}()
+}
+
+// Alerts returns a copy of the alerts, the newest alert first
+func (e *AlertEngine) Alerts() []Alert {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	alerts := make([]Alert, len(e.alerts))
+	for idx, alert := range e.alerts {
+		alerts[len(alerts)-1-idx] = *alert
+	}
+	return alerts
+}
+
+// Acknowledge marks the alert with given id as acknowledged, id 0 acknowledges all alerts
+func (e *AlertEngine) Acknowledge(id int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for _, alert := range e.alerts {
+		if id == 0 || alert.ID == id {
+			alert.Acknowledged = true
+		}
+	}
+}
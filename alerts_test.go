@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// firingRules returns the rules of the firing alerts
+func firingRules(engine *AlertEngine) map[string]bool {
+	rules := make(map[string]bool)
+	for _, alert := range engine.Alerts() {
+		if alert.IsFiring() {
+			rules[alert.Rule] = true
+		}
+	}
+	return rules
+}
+
+func TestAlertEngineCountsRestartsPerRule(t *testing.T) {
+	engine := NewAlertEngine()
+	var rules []AlertRule
+	for _, text := range []string{"restarted 2 times in 1m", "restarted 3 times in 10m"} {
+		rule, err := ParseAlertRule(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rule)
+	}
+	engine.SetRules(rules)
+
+	start := time.Now()
+	restart := func(created int64, after time.Duration) {
+		engine.Evaluate([]ContainerData{{ID: "c1", State: ContainerRunning, Created: created}}, start.Add(after))
+	}
+	restart(1, 0)
+	restart(2, 2*time.Minute)
+	restart(3, 4*time.Minute)
+	restart(4, 6*time.Minute)
+
+	// the restarts outside of the short window still count for the long window
+	firing := firingRules(engine)
+	if firing["restarted 2 times in 1m"] {
+		t.Errorf("expected restarts minutes apart not to fire the rule of the short window")
+	}
+	if !firing["restarted 3 times in 10m"] {
+		t.Errorf("expected restarts within 10m to fire the rule of the long window")
+	}
+
+	restart(5, 6*time.Minute+30*time.Second)
+	if !firingRules(engine)["restarted 2 times in 1m"] {
+		t.Errorf("expected restarts within 1m to fire the rule of the short window")
+	}
+}
+
+func TestAlertEngineDoesNotTreatStartingHealthAsUnhealthy(t *testing.T) {
+	engine := NewAlertEngine()
+	rule, err := ParseAlertRule("health becomes unhealthy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetRules([]AlertRule{rule})
+
+	now := time.Now()
+	for i, health := range []string{"healthy", "starting", "healthy", "unhealthy"} {
+		data := ContainerData{ID: "c1", State: ContainerRunning, HealthStatus: healthStateFromStatus(health)}
+		engine.Evaluate([]ContainerData{data}, now.Add(time.Duration(i)*time.Second))
+		if firing := firingRules(engine)[rule.Text]; firing != (health == "unhealthy") {
+			t.Errorf("unexpected firing %v of rule when health became %s", firing, health)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// apiContainer is the JSON representation of a container in the container list
+type apiContainer struct {
+	ID                   string         `json:"id"`
+	Host                 string         `json:"host"`
+	Name                 string         `json:"name"`
+	AlternativeName      string         `json:"alternativeName"`
+	Image                string         `json:"image"`
+	State                ContainerState `json:"state"`
+	Created              int64          `json:"created"`
+	DockerComposeProject string         `json:"composeProject,omitempty"`
+	DockerComposeService string         `json:"composeService,omitempty"`
+	LastUpdated          int64          `json:"lastUpdated"`
+	CpuPercent           float64        `json:"cpuPercent"`
+	CpuThrottledPercent  float64        `json:"cpuThrottledPercent"`
+	Memory               uint64         `json:"memory"`
+	MemoryLimit          uint64         `json:"memoryLimit"`
+	MemoryPercent        float64        `json:"memoryPercent"`
+	NetworkRx            uint64         `json:"networkRx"`
+	NetworkTx            uint64         `json:"networkTx"`
+	BlockRead            uint64         `json:"blockRead"`
+	BlockWrite           uint64         `json:"blockWrite"`
+	PIDs                 uint64         `json:"pids"`
+	HealthStatus         HealthState    `json:"healthStatus"`
+	ExitCode             int            `json:"exitCode"`
+	OOMKilled            bool           `json:"oomKilled"`
+	FinishedAt           int64          `json:"finishedAt,omitempty"`
+}
+
+// apiContainerDetail is the JSON representation of a single container including its history
+type apiContainerDetail struct {
+	apiContainer
+	DockerComposeProjectDir      string             `json:"composeProjectDir,omitempty"`
+	DockerComposeContainerNumber int                `json:"composeContainerNumber,omitempty"`
+	EnvVars                      map[string]string  `json:"envVars"`
+	Labels                       map[string]string  `json:"labels"`
+	History                      map[string]History `json:"history"`
+}
+
+// apiTotals is the JSON representation of the totals of all containers of a host or of all hosts
+type apiTotals struct {
+	Host       string  `json:"host,omitempty"`
+	Connected  bool    `json:"connected"`
+	Containers int     `json:"containers"`
+	CpuPercent float64 `json:"cpuPercent"`
+	Memory     uint64  `json:"memory"`
+	NetworkRx  uint64  `json:"networkRx"`
+	NetworkTx  uint64  `json:"networkTx"`
+}
+
+func newApiContainer(data ContainerData) apiContainer {
+	return apiContainer{
+		ID:                   data.ID,
+		Host:                 data.Host,
+		Name:                 data.Name,
+		AlternativeName:      data.AlternativeName,
+		Image:                data.Image,
+		State:                data.State,
+		Created:              data.Created,
+		DockerComposeProject: data.DockerComposeProject,
+		DockerComposeService: data.DockerComposeService,
+		LastUpdated:          data.LastUpdated,
+		CpuPercent:           data.CpuPercent,
+		CpuThrottledPercent:  data.CpuThrottledPercent,
+		Memory:               data.Memory,
+		MemoryLimit:          data.MemoryLimit,
+		MemoryPercent:        data.MemoryPercent,
+		NetworkRx:            data.NetworkRx,
+		NetworkTx:            data.NetworkTx,
+		BlockRead:            data.BlockRead,
+		BlockWrite:           data.BlockWrite,
+		PIDs:                 data.PIDs,
+		HealthStatus:         data.HealthStatus,
+		ExitCode:             data.ExitCode,
+		OOMKilled:            data.OOMKilled,
+		FinishedAt:           data.FinishedAt,
+	}
+}
+
+func newApiContainerDetail(data ContainerData) apiContainerDetail {
+	return apiContainerDetail{
+		apiContainer:                 newApiContainer(data),
+		DockerComposeProjectDir:      data.DockerComposeProjectDir,
+		DockerComposeContainerNumber: data.DockerComposeContainerNumber,
+		EnvVars:                      data.EnvVars,
+		Labels:                       data.Labels,
+		History: map[string]History{
+			"cpuPercent":          data.CpuPercentHistory,
+			"cpuThrottledPercent": data.CpuThrottledPercentHistory,
+			"memory":              data.MemoryHistory,
+			"networkRx":           data.NetworkRxHistory,
+			"networkTx":           data.NetworkTxHistory,
+			"blockRead":           data.BlockReadHistory,
+			"blockWrite":          data.BlockWriteHistory,
+			"pids":                data.PIDsHistory,
+		},
+	}
+}
+
+// newApiTotals sums up given containers, host is only set for totals of a single host
+func newApiTotals(host HostData, data []ContainerData) apiTotals {
+	totals := apiTotals{Host: host.Name, Connected: host.Connected}
+	for _, d := range data {
+		if (len(host.Name) > 0 && d.Host != host.Name) || d.State.IsTombstone() {
+			continue
+		}
+		totals.Containers++
+		totals.CpuPercent += d.CpuPercent
+		totals.Memory += d.Memory
+		totals.NetworkRx += d.NetworkRx
+		totals.NetworkTx += d.NetworkTx
+	}
+	return totals
+}
+
+// serveApi serves the live container snapshot as JSON and as Prometheus metrics on given address until ctx is done
+func serveApi(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/containers", handleApiContainers)
+	mux.HandleFunc("GET /api/containers/{id}", handleApiContainer)
+	mux.HandleFunc("POST /api/containers/{id}/start", handleApiContainerAction(startContainer))
+	mux.HandleFunc("POST /api/containers/{id}/stop", handleApiContainerAction(stopContainer))
+	mux.HandleFunc("POST /api/containers/{id}/restart", handleApiContainerAction(restartContainer))
+	mux.HandleFunc("POST /api/containers/{id}/pause", handleApiContainerAction(pauseContainer))
+	mux.HandleFunc("POST /api/containers/{id}/unpause", handleApiContainerAction(unpauseContainer))
+	mux.HandleFunc("POST /api/containers/{id}/kill", handleApiContainerKill)
+	mux.HandleFunc("GET /api/totals", handleApiTotals)
+	mux.HandleFunc("GET /metrics", handleMetrics)
+
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		fmt.Printf("Serving api at http://%s/api\n", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Failed to serve api: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Printf("Failed to write api response: %v\n", err)
+	}
+}
+
+func writeJsonError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJson(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+// findContainerData returns the snapshot of the container with given id or id prefix
+func findContainerData(data []ContainerData, id string) (ContainerData, bool) {
+	for _, d := range data {
+		if d.ID == id || (len(id) >= 12 && strings.HasPrefix(d.ID, id)) {
+			return d, true
+		}
+	}
+	return ContainerData{}, false
+}
+
+func handleApiContainers(w http.ResponseWriter, _ *http.Request) {
+	data, _ := collectContainerData()
+	sort.SliceStable(data, func(i int, j int) bool {
+		return data[i].AlternativeName < data[j].AlternativeName
+	})
+	containers := make([]apiContainer, len(data))
+	for i := range data {
+		containers[i] = newApiContainer(data[i])
+	}
+	writeJson(w, http.StatusOK, containers)
+}
+
+func handleApiContainer(w http.ResponseWriter, r *http.Request) {
+	data, _ := collectContainerData()
+	d, ok := findContainerData(data, r.PathValue("id"))
+	if !ok {
+		writeJsonError(w, http.StatusNotFound, "unknown container %s", r.PathValue("id"))
+		return
+	}
+	writeJson(w, http.StatusOK, newApiContainerDetail(d))
+}
+
+func handleApiTotals(w http.ResponseWriter, _ *http.Request) {
+	data, hosts := collectContainerData()
+	connected := len(hosts) > 0
+	perHost := make([]apiTotals, len(hosts))
+	for i, host := range hosts {
+		perHost[i] = newApiTotals(host, data)
+		connected = connected && host.Connected
+	}
+	writeJson(w, http.StatusOK, map[string]any{
+		"total": newApiTotals(HostData{Connected: connected}, data),
+		"hosts": perHost,
+	})
+}
+
+// handleApiContainerAction returns a handler applying given action to the container of the request
+func handleApiContainerAction(action func(id string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, _ := collectContainerData()
+		d, ok := findContainerData(data, r.PathValue("id"))
+		if !ok {
+			writeJsonError(w, http.StatusNotFound, "unknown container %s", r.PathValue("id"))
+			return
+		}
+		go action(d.ID)
+		writeJson(w, http.StatusAccepted, newApiContainer(d))
+	}
+}
+
+// handleApiContainerKill sends the signal given by query parameter "signal" to the container, defaults to SIGKILL
+func handleApiContainerKill(w http.ResponseWriter, r *http.Request) {
+	signal := r.URL.Query().Get("signal")
+	if len(signal) == 0 {
+		signal = "SIGKILL"
+	}
+	if !slices.Contains(KillSignals, signal) {
+		writeJsonError(w, http.StatusBadRequest, "unsupported signal %s, expected one of %s", signal, strings.Join(KillSignals, ", "))
+		return
+	}
+	handleApiContainerAction(func(id string) {
+		killContainer(id, signal)
+	})(w, r)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	types_container "github.com/docker/docker/api/types/container"
+	types_event "github.com/docker/docker/api/types/events"
+	"io"
+	"os"
+)
+
+// ContainerBackend is the container runtime the collector talks to.
+// Payloads use the types of the Docker Engine API, it is the common denominator of the supported runtimes.
+type ContainerBackend interface {
+	// Name returns a short name of the backend, e.g. "docker"
+	Name() string
+	// Ping returns an error if the runtime is not reachable
+	Ping(ctx context.Context) error
+	// ContainerList returns the running containers, or all containers including exited ones if all is set
+	ContainerList(ctx context.Context, all bool) ([]types_container.Summary, error)
+	ContainerInspect(ctx context.Context, id string) (types_container.InspectResponse, error)
+	// ContainerInspectRaw returns the inspect result as JSON like sent by the runtime, including fields unknown to the API types
+	ContainerInspectRaw(ctx context.Context, id string) ([]byte, error)
+	// ContainerStats opens a stream of stats samples of given container
+	ContainerStats(ctx context.Context, id string) (StatsStream, error)
+	// ContainerLogs opens the log stream of given container, it is multiplexed unless the container has a TTY
+	ContainerLogs(ctx context.Context, id string, options types_container.LogsOptions) (io.ReadCloser, error)
+	// Events returns a channel of runtime events and a channel of errors, events stop being delivered when ctx is done.
+	// The channel of events may be left open, e.g. by the Docker client.
+	Events(ctx context.Context) (<-chan types_event.Message, <-chan error)
+	ContainerStart(ctx context.Context, id string) error
+	ContainerStop(ctx context.Context, id string) error
+	ContainerRestart(ctx context.Context, id string) error
+	ContainerPause(ctx context.Context, id string) error
+	ContainerUnpause(ctx context.Context, id string) error
+	// ContainerKill sends given signal to the main process of the container, e.g. "SIGKILL"
+	ContainerKill(ctx context.Context, id string, signal string) error
+	// ContainerTop lists the processes of given container, arguments are passed to ps
+	ContainerTop(ctx context.Context, id string, arguments []string) (types_container.TopResponse, error)
+	// TopPIDScope returns the pid namespace of the pids listed by ContainerTop
+	TopPIDScope() PIDScope
+	// ContainerExecCreate creates a process to be executed in given container, returns the ID of the exec instance
+	ContainerExecCreate(ctx context.Context, id string, options types_container.ExecOptions) (string, error)
+	// ContainerExecAttach starts the exec instance and returns a connection to its stdin and output
+	ContainerExecAttach(ctx context.Context, execID string, options types_container.ExecAttachOptions) (types.HijackedResponse, error)
+	// ContainerExecResize changes the size of the TTY of the exec instance
+	ContainerExecResize(ctx context.Context, execID string, height uint, width uint) error
+	// Close releases all resources of the backend
+	Close() error
+}
+
+// PIDScope is the pid namespace of the processes listed by a backend
+type PIDScope int
+
+const (
+	ContainerPIDs  PIDScope = iota // pids in the pid namespace of the container
+	LocalHostPIDs                  // pids of this host
+	RemoteHostPIDs                 // pids of the remote host of the runtime, they can't be mapped to pids of the container
+)
+
+// StatsStream delivers consecutive stats samples of a single container
+type StatsStream interface {
+	// OSType returns the operating system of the runtime, e.g. "linux" or "windows"
+	OSType() string
+	// Next blocks until the next stats sample is available, returns io.EOF at end of stream
+	Next() (*types_container.StatsResponse, error)
+	Close() error
+}
+
+// BackendFactory creates a new backend, it is called for every (re-)connect of the collector
+type BackendFactory func() (ContainerBackend, error)
+
+// backendFactoryByName returns the factory of the backend with given name and the name of its entry in the daemon menu.
+// Backend "auto" prefers a configured or running Docker daemon and falls back to a discovered Podman socket.
+func backendFactoryByName(name string) (BackendFactory, string, error) {
+	switch name {
+	case "docker":
+		return NewDockerBackendFromEnv, DefaultDockerContext, nil
+	case "podman":
+		sockets := discoverPodmanSockets()
+		if len(sockets) == 0 {
+			return nil, "", fmt.Errorf("no podman socket found at %v", podmanSocketCandidates())
+		}
+		return podmanBackendFactory(sockets[0]), podmanDaemonName(sockets[0]), nil
+	case "auto":
+		if _, ok := os.LookupEnv("DOCKER_HOST"); ok {
+			return NewDockerBackendFromEnv, DefaultDockerContext, nil
+		}
+		if _, err := os.Stat("/var/run/docker.sock"); err == nil {
+			return NewDockerBackendFromEnv, DefaultDockerContext, nil
+		}
+		if sockets := discoverPodmanSockets(); len(sockets) > 0 {
+			return podmanBackendFactory(sockets[0]), podmanDaemonName(sockets[0]), nil
+		}
+		return NewDockerBackendFromEnv, DefaultDockerContext, nil
+	}
+	return nil, "", fmt.Errorf("unknown backend %q, expected one of auto, docker or podman", name)
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	types_container "github.com/docker/docker/api/types/container"
+	types_event "github.com/docker/docker/api/types/events"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeContainer is a container of the fakeBackend
+type fakeContainer struct {
+	ID       string
+	Name     string
+	Status   string // e.g. "running" or "exited"
+	Health   string // status of the healthcheck, no healthcheck if empty
+	ExitCode int
+	Finished time.Time // time the container exited
+	Labels   map[string]string
+	Top      types_container.TopResponse
+
+	stats chan *types_container.StatsResponse
+}
+
+// fakeBackend is an in-memory ContainerBackend, tests script its containers, stats samples and events
+type fakeBackend struct {
+	mutex      sync.Mutex
+	containers []*fakeContainer
+	events     chan types_event.Message
+	calls      []string // control calls received, e.g. "stop c1"
+	pingErr    error
+	statsErr   error // error of following the stats of containers
+	closed     int   // number of times the backend got closed
+}
+
+var _ ContainerBackend = &fakeBackend{}
+
+func newFakeBackend(containers ...fakeContainer) *fakeBackend {
+	b := &fakeBackend{events: make(chan types_event.Message)}
+	for _, container := range containers {
+		b.addContainer(container)
+	}
+	return b
+}
+
+// factory returns a factory handing out this backend on every (re-)connect
+func (b *fakeBackend) factory() BackendFactory {
+	return func() (ContainerBackend, error) {
+		return b, nil
+	}
+}
+
+func (b *fakeBackend) addContainer(container fakeContainer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	container.stats = make(chan *types_container.StatsResponse, 16)
+	b.containers = append(b.containers, &container)
+}
+
+func (b *fakeBackend) container(id string) (*fakeContainer, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, container := range b.containers {
+		if container.ID == id {
+			return container, true
+		}
+	}
+	return nil, false
+}
+
+// setStatus changes the state of a container, e.g. before emitting the matching event
+func (b *fakeBackend) setStatus(id string, status string, exitCode int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, container := range b.containers {
+		if container.ID == id {
+			container.Status = status
+			container.ExitCode = exitCode
+		}
+	}
+}
+
+func (b *fakeBackend) setHealth(id string, health string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, container := range b.containers {
+		if container.ID == id {
+			container.Health = health
+		}
+	}
+}
+
+// setLabels replaces the labels of a container, e.g. before emitting the event making the collector inspect it again
+func (b *fakeBackend) setLabels(id string, labels map[string]string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, container := range b.containers {
+		if container.ID == id {
+			container.Labels = labels
+		}
+	}
+}
+
+// emit sends a container event to the collector
+func (b *fakeBackend) emit(t *testing.T, id string, action string, attributes map[string]string) {
+	t.Helper()
+	event := types_event.Message{
+		Type:   types_event.ContainerEventType,
+		Action: types_event.Action(action),
+		Actor:  types_event.Actor{ID: id, Attributes: attributes},
+		Time:   time.Now().Unix(),
+	}
+	select {
+	case b.events <- event:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("nobody received event %s of container %s", action, id)
+	}
+}
+
+// sendStats queues a stats sample of the container with given cpu usage and memory
+func (b *fakeBackend) sendStats(t *testing.T, id string, cpuTotal uint64, systemTotal uint64, memory uint64) {
+	t.Helper()
+	container, ok := b.container(id)
+	if !ok {
+		t.Fatalf("unknown container %s", id)
+	}
+	stats := &types_container.StatsResponse{}
+	stats.Read = time.Now()
+	stats.CPUStats.CPUUsage.TotalUsage = cpuTotal
+	stats.CPUStats.SystemUsage = systemTotal
+	stats.CPUStats.OnlineCPUs = 1
+	stats.MemoryStats.Usage = memory
+	stats.MemoryStats.Limit = 1024 * 1024 * 1024
+	stats.PidsStats.Current = 1
+	container.stats <- stats
+}
+
+func (b *fakeBackend) recordCall(call string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.calls = append(b.calls, call)
+}
+
+func (b *fakeBackend) Calls() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return slices.Clone(b.calls)
+}
+
+func (b *fakeBackend) Name() string {
+	return "fake"
+}
+
+func (b *fakeBackend) Ping(ctx context.Context) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.pingErr
+}
+
+func (b *fakeBackend) ContainerList(ctx context.Context, all bool) ([]types_container.Summary, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	containers := make([]types_container.Summary, 0, len(b.containers))
+	for _, container := range b.containers {
+		if !all && container.Status != "running" {
+			continue
+		}
+		containers = append(containers, types_container.Summary{
+			ID:     container.ID,
+			Names:  []string{"/" + container.Name},
+			Labels: container.Labels,
+			State:  container.Status,
+		})
+	}
+	return containers, nil
+}
+
+func (b *fakeBackend) ContainerInspect(ctx context.Context, id string) (types_container.InspectResponse, error) {
+	container, ok := b.container(id)
+	if !ok {
+		return types_container.InspectResponse{}, fmt.Errorf("no such container: %s", id)
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	state := &types_container.State{
+		Status:    container.Status,
+		Running:   container.Status == "running",
+		Paused:    container.Status == "paused",
+		ExitCode:  container.ExitCode,
+		StartedAt: time.Now().Format(time.RFC3339Nano),
+	}
+	if !container.Finished.IsZero() {
+		state.FinishedAt = container.Finished.Format(time.RFC3339Nano)
+	}
+	if len(container.Health) > 0 {
+		state.Health = &types_container.Health{Status: container.Health}
+	}
+	return types_container.InspectResponse{
+		ContainerJSONBase: &types_container.ContainerJSONBase{
+			ID:    container.ID,
+			Name:  "/" + container.Name,
+			Image: "sha256:fake",
+			State: state,
+		},
+		Config: &types_container.Config{Labels: container.Labels},
+	}, nil
+}
+
+func (b *fakeBackend) ContainerInspectRaw(ctx context.Context, id string) ([]byte, error) {
+	inspect, err := b.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf(`{"Id":%q,"Name":%q}`, inspect.ID, inspect.Name)), nil
+}
+
+func (b *fakeBackend) ContainerStats(ctx context.Context, id string) (StatsStream, error) {
+	container, ok := b.container(id)
+	if !ok {
+		return nil, fmt.Errorf("no such container: %s", id)
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.statsErr != nil {
+		return nil, b.statsErr
+	}
+	return &fakeStatsStream{ctx: ctx, stats: container.stats}, nil
+}
+
+func (b *fakeBackend) ContainerLogs(ctx context.Context, id string, options types_container.LogsOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+// Events forwards the events emitted by the test until ctx is done
+func (b *fakeBackend) Events(ctx context.Context) (<-chan types_event.Message, <-chan error) {
+	events := make(chan types_event.Message)
+	errs := make(chan error)
+	go func() {
+		defer close(events)
+		defer close(errs)
+		for {
+			select {
+			case event := <-b.events:
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, errs
+}
+
+func (b *fakeBackend) ContainerStart(ctx context.Context, id string) error {
+	b.recordCall("start " + id)
+	return nil
+}
+
+func (b *fakeBackend) ContainerStop(ctx context.Context, id string) error {
+	b.recordCall("stop " + id)
+	return nil
+}
+
+func (b *fakeBackend) ContainerRestart(ctx context.Context, id string) error {
+	b.recordCall("restart " + id)
+	return nil
+}
+
+func (b *fakeBackend) ContainerPause(ctx context.Context, id string) error {
+	b.recordCall("pause " + id)
+	return nil
+}
+
+func (b *fakeBackend) ContainerUnpause(ctx context.Context, id string) error {
+	b.recordCall("unpause " + id)
+	return nil
+}
+
+func (b *fakeBackend) ContainerKill(ctx context.Context, id string, signal string) error {
+	b.recordCall("kill " + id + " " + signal)
+	return nil
+}
+
+func (b *fakeBackend) ContainerTop(ctx context.Context, id string, arguments []string) (types_container.TopResponse, error) {
+	container, ok := b.container(id)
+	if !ok {
+		return types_container.TopResponse{}, fmt.Errorf("no such container: %s", id)
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return container.Top, nil
+}
+
+func (b *fakeBackend) TopPIDScope() PIDScope {
+	return ContainerPIDs
+}
+
+func (b *fakeBackend) ContainerExecCreate(ctx context.Context, id string, options types_container.ExecOptions) (string, error) {
+	return "", errors.New("exec is not supported by the fake backend")
+}
+
+func (b *fakeBackend) ContainerExecAttach(ctx context.Context, execID string, options types_container.ExecAttachOptions) (types.HijackedResponse, error) {
+	return types.HijackedResponse{}, errors.New("exec is not supported by the fake backend")
+}
+
+func (b *fakeBackend) ContainerExecResize(ctx context.Context, execID string, height uint, width uint) error {
+	return errors.New("exec is not supported by the fake backend")
+}
+
+func (b *fakeBackend) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed++
+	return nil
+}
+
+// fakeStatsStream delivers the stats samples queued by the test until its context is done
+type fakeStatsStream struct {
+	ctx   context.Context
+	stats chan *types_container.StatsResponse
+}
+
+func (s *fakeStatsStream) OSType() string {
+	return "linux"
+}
+
+func (s *fakeStatsStream) Next() (*types_container.StatsResponse, error) {
+	select {
+	case stats := <-s.stats:
+		return stats, nil
+	case <-s.ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+func (s *fakeStatsStream) Close() error {
+	return nil
+}
+
+// waitFor polls the condition until it is met, the test fails if it isn't met within a few seconds
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,584 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	types_event "github.com/docker/docker/api/types/events"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// built-in defaults of tunables of the collector, see Config
+var (
+	// PingInterval is the interval the runtime is pinged in to detect it got unavailable
+	PingInterval = 5 * time.Second
+	// RetryInterval is the delay before reconnecting to an unavailable runtime
+	RetryInterval = 5 * time.Second
+	// ExitedRetention is the duration exited containers are kept as tombstones after they finished, 0 keeps them forever
+	ExitedRetention = 24 * time.Hour
+)
+
+// Collector follows the containers of a single container runtime endpoint
+type Collector struct {
+	switched chan bool
+
+	containerInfoMutex sync.RWMutex
+	containerInfo      map[string]*ContainerInfo
+	connected          bool
+	host               string
+	newBackend         BackendFactory
+
+	workers sync.WaitGroup // goroutines of the current backend adding or removing followed containers
+}
+
+// NewCollector creates a collector for the runtime named host, the backend is created by given factory
+func NewCollector(host string, newBackend BackendFactory) *Collector {
+	return &Collector{
+		switched:      make(chan bool, 1),
+		containerInfo: make(map[string]*ContainerInfo, 0),
+		host:          host,
+		newBackend:    newBackend,
+	}
+}
+
+// Host returns the name of the followed runtime
+func (c *Collector) Host() string {
+	c.containerInfoMutex.RLock()
+	defer c.containerInfoMutex.RUnlock()
+	return c.host
+}
+
+// SwitchBackend makes the collector follow another runtime, containers of the current runtime are dropped
+func (c *Collector) SwitchBackend(host string, newBackend BackendFactory) {
+	c.containerInfoMutex.Lock()
+	c.host = host
+	c.newBackend = newBackend
+	c.containerInfoMutex.Unlock()
+	select {
+	case c.switched <- true:
+	default:
+		// switch is already pending
+	}
+}
+
+func (c *Collector) backend() (string, BackendFactory) {
+	c.containerInfoMutex.RLock()
+	defer c.containerInfoMutex.RUnlock()
+	return c.host, c.newBackend
+}
+
+// Connected returns true while the runtime is reachable
+func (c *Collector) Connected() bool {
+	c.containerInfoMutex.RLock()
+	defer c.containerInfoMutex.RUnlock()
+	return c.connected
+}
+
+func (c *Collector) setConnected(connected bool) {
+	c.containerInfoMutex.Lock()
+	defer c.containerInfoMutex.Unlock()
+	c.connected = connected
+}
+
+// Container returns the info of the container with given id
+func (c *Collector) Container(id string) (*ContainerInfo, bool) {
+	c.containerInfoMutex.RLock()
+	defer c.containerInfoMutex.RUnlock()
+	info, ok := c.containerInfo[id]
+	return info, ok
+}
+
+// ContainerData returns a snapshot of the data of all followed containers
+func (c *Collector) ContainerData() []ContainerData {
+	c.containerInfoMutex.RLock()
+	defer c.containerInfoMutex.RUnlock()
+
+	data := make([]ContainerData, 0, len(c.containerInfo))
+	for _, info := range c.containerInfo {
+		info.mutex.RLock()
+		snapshot := info.Data
+		snapshot.CloneHistories()
+		info.mutex.RUnlock()
+		data = append(data, snapshot)
+	}
+	return data
+}
+
+// getDockerStats follows containers of the runtime, returned channel is closed when runtime got unavailable
+func (c *Collector) getDockerStats(ctx context.Context) chan bool {
+	done := make(chan bool, 1)
+
+	host, newBackend := c.backend()
+	backend, err := newBackend()
+	if err != nil {
+		fmt.Printf("Failed to get container backend: %v\n", err)
+		close(done)
+		return done
+	}
+	go func() {
+		<-ctx.Done()
+		if err := backend.Close(); err != nil {
+			fmt.Printf("Failed to close container backend: %v\n", err)
+		}
+	}()
+
+	// follow stats of given container until it stopped
+	follow := func(info *ContainerInfo) {
+		statsCtx, statsCancel := context.WithCancel(context.Background())
+		info.mutex.Lock()
+		info.following = true
+		info.mutex.Unlock()
+		info.OnStopped = func() {
+			statsCancel()
+			info.mutex.Lock()
+			info.following = false
+			info.mutex.Unlock()
+			if historyStore != nil {
+				historyStore.Release(info.Data.ID)
+			}
+			// keep the container as tombstone with its exit state
+			c.inspectContainer(ctx, backend, info)
+		}
+		fmt.Printf("Following container: %s (%s)\n", info.Data.AlternativeName, info.Data.ID)
+		go updateContainerStats(statsCtx, backend, info)
+
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					// outer context closed, stop stats context too
+					statsCancel()
+				case <-statsCtx.Done():
+					// stats context closed, we're done
+					return
+				case <-time.After(1 * time.Second):
+					//
+				}
+			}
+		}()
+	}
+
+	// handle container info is sent through the channel and we will start following container stats
+	newContainerIds := make(chan string, 1)
+	addContainer := func(id string) {
+		select {
+		case newContainerIds <- id:
+		case <-ctx.Done():
+		}
+	}
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		for {
+			var id string
+			select {
+			case id = <-newContainerIds:
+			case <-ctx.Done():
+				return
+			}
+			if info, ok := c.Container(id); ok {
+				// a stopped container got started again
+				info.mutex.RLock()
+				following := info.following
+				info.mutex.RUnlock()
+				if state := c.inspectContainer(ctx, backend, info); !following && (state == ContainerRunning || state == ContainerPaused) {
+					follow(info)
+				}
+				continue
+			}
+
+			info := NewContainerInfo(id)
+			info.Data.Host = host
+			state := c.inspectContainer(ctx, backend, info)
+			if expiredTombstone(info.Data, currentTime()) {
+				continue
+			}
+			if historyStore != nil {
+				if err := historyStore.Restore(&info.Data); err != nil {
+					fmt.Printf("Failed to restore history of container %s: %v\n", info.Data.ID, err)
+				}
+			}
+
+			info.Start = func() {
+				info.mutex.Lock()
+				if info.Data.State == ContainerExited || info.Data.State == ContainerCreated {
+					prevState := info.Data.State
+					info.Data.State = ContainerStarting
+					info.mutex.Unlock()
+					fmt.Printf("Starting container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
+					err := backend.ContainerStart(ctx, info.Data.ID)
+					if err != nil {
+						fmt.Printf("Failed to start container %s (%s): %v\n", info.Data.AlternativeName, info.Data.ID, err)
+						info.mutex.Lock()
+						info.Data.State = prevState
+						info.mutex.Unlock()
+					}
+				} else {
+					info.mutex.Unlock()
+				}
+			}
+			info.Stop = func() {
+				info.mutex.Lock()
+				if info.Data.State == ContainerRunning {
+					info.Data.State = ContainerStopping
+					info.mutex.Unlock()
+					fmt.Printf("Stopping container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
+					err := backend.ContainerStop(ctx, info.Data.ID)
+					if err != nil {
+						fmt.Printf("Failed to stop container %s (%s): %v", info.Data.AlternativeName, info.Data.ID, err)
+					}
+				} else {
+					info.mutex.Unlock()
+				}
+			}
+			info.Restart = func() {
+				info.mutex.Lock()
+				defer info.mutex.Unlock()
+				// containers that exited get started again, e.g. from the notification of their death
+				if state := info.Data.State; state == ContainerRunning || state == ContainerExited || state == ContainerCreated {
+					info.Data.State = ContainerRestarting
+					fmt.Printf("Restarting container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
+					err := backend.ContainerRestart(ctx, info.Data.ID)
+					if err != nil {
+						fmt.Printf("Failed to restart container %s (%s): %v", info.Data.AlternativeName, info.Data.ID, err)
+					}
+				}
+			}
+			info.Logs = func(logCtx context.Context, tail string, line func(LogLine)) error {
+				return followContainerLogs(logCtx, backend, info.Data.ID, tail, line)
+			}
+			info.Exec = func(execCtx context.Context, cmd []string, rows int, cols int) (*ExecSession, error) {
+				return startExecSession(execCtx, backend, info.Data.ID, cmd, rows, cols)
+			}
+			info.Inspect = func(inspectCtx context.Context) ([]byte, error) {
+				return backend.ContainerInspectRaw(inspectCtx, info.Data.ID)
+			}
+			info.Processes = func(topCtx context.Context) ([]Process, error) {
+				return listContainerProcesses(topCtx, backend, info.Data.ID)
+			}
+			info.SignalProcess = func(pid int, signal string) {
+				fmt.Printf("Sending %s to process %d of container %s (%s)...\n", signal, pid, info.Data.AlternativeName, info.Data.ID)
+				if err := signalContainerProcess(ctx, backend, info.Data.ID, pid, signal); err != nil {
+					fmt.Printf("Failed to send %s to process %d of container %s (%s): %v\n", signal, pid, info.Data.AlternativeName, info.Data.ID, err)
+				}
+			}
+			info.Pause = func() {
+				info.mutex.RLock()
+				running := info.Data.State == ContainerRunning
+				info.mutex.RUnlock()
+				if running {
+					fmt.Printf("Pausing container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
+					if err := backend.ContainerPause(ctx, info.Data.ID); err != nil {
+						fmt.Printf("Failed to pause container %s (%s): %v\n", info.Data.AlternativeName, info.Data.ID, err)
+					}
+				}
+			}
+			info.Unpause = func() {
+				info.mutex.RLock()
+				paused := info.Data.State == ContainerPaused
+				info.mutex.RUnlock()
+				if paused {
+					fmt.Printf("Unpausing container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
+					if err := backend.ContainerUnpause(ctx, info.Data.ID); err != nil {
+						fmt.Printf("Failed to unpause container %s (%s): %v\n", info.Data.AlternativeName, info.Data.ID, err)
+					}
+				}
+			}
+			info.Kill = func(signal string) {
+				info.mutex.RLock()
+				alive := info.Data.State == ContainerRunning || info.Data.State == ContainerPaused
+				info.mutex.RUnlock()
+				if alive {
+					fmt.Printf("Sending %s to container %s (%s)...\n", signal, info.Data.AlternativeName, info.Data.ID)
+					if err := backend.ContainerKill(ctx, info.Data.ID, signal); err != nil {
+						fmt.Printf("Failed to send %s to container %s (%s): %v\n", signal, info.Data.AlternativeName, info.Data.ID, err)
+					}
+				}
+			}
+
+			c.containerInfoMutex.Lock()
+			c.containerInfo[id] = info
+			c.containerInfoMutex.Unlock()
+			if state == ContainerRunning || state == ContainerPaused {
+				follow(info)
+			}
+		}
+	}()
+
+	// listen to docker events related to starting & stopping containers
+	events, _ := backend.Events(ctx)
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		signaled := make(map[string][]int) // signals sent to containers since they started, by container id
+		for {
+			var event types_event.Message
+			select {
+			case received, ok := <-events:
+				if !ok {
+					return
+				}
+				event = received
+			case <-ctx.Done():
+				return
+			}
+			fmt.Printf("Container Event: %s %s %s\n", event.Type, event.Status, event.Action)
+			if event.Type == "container" {
+				switch event.Action {
+				case "kill":
+					if signal, err := strconv.Atoi(event.Actor.Attributes["signal"]); err == nil {
+						signaled[event.Actor.ID] = append(signaled[event.Actor.ID], signal)
+					}
+				case "start":
+					delete(signaled, event.Actor.ID)
+				}
+				if event.Action == "die" {
+					exitCode, _ := strconv.Atoi(event.Actor.Attributes["exitCode"])
+					signals, killed := signaled[event.Actor.ID]
+					delete(signaled, event.Actor.ID)
+					if killed && requestedExit(exitCode, signals) {
+						fmt.Printf("Container exited on request with exit code %d: %s\n", exitCode, event.Actor.ID)
+					} else {
+						notify(c.eventNotification(event.Actor.ID, string(event.Action), event.Actor.Attributes))
+					}
+				}
+				if event.Action == "start" || event.Action == "stop" || event.Action == "oom" {
+					notify(c.eventNotification(event.Actor.ID, string(event.Action), event.Actor.Attributes))
+				}
+				if event.Action == "start" || event.Action == "create" {
+					fmt.Printf("Container %s: %s\n", event.Action, event.Actor.ID)
+					addContainer(event.Actor.ID)
+				}
+				if event.Action == "die" || event.Action == "stop" {
+					if info, ok := c.Container(event.Actor.ID); ok {
+						info.mutex.Lock()
+						following := info.following
+						if following {
+							info.Data.State = ContainerStopped
+						}
+						info.mutex.Unlock()
+						if following {
+							fmt.Printf("Container stopped: %s (%s)\n", info.Data.AlternativeName, event.Actor.ID)
+							info.OnStopped()
+						}
+					}
+				}
+				if event.Action == "pause" || event.Action == "unpause" {
+					if info, ok := c.Container(event.Actor.ID); ok {
+						info.mutex.Lock()
+						if event.Action == "pause" {
+							info.Data.State = ContainerPaused
+						} else {
+							info.Data.State = ContainerRunning
+						}
+						info.mutex.Unlock()
+						fmt.Printf("Container %sd: %s (%s)\n", event.Action, info.Data.AlternativeName, event.Actor.ID)
+					}
+				}
+				if event.Action == "kill" {
+					fmt.Printf("Container got signal %s: %s\n", event.Actor.Attributes["signal"], event.Actor.ID)
+				}
+				if event.Action == "destroy" {
+					if info, ok := c.Container(event.Actor.ID); ok {
+						fmt.Printf("Container removed: %s (%s)\n", info.Data.AlternativeName, event.Actor.ID)
+						c.containerInfoMutex.Lock()
+						delete(c.containerInfo, event.Actor.ID)
+						c.containerInfoMutex.Unlock()
+					}
+				}
+			}
+		}
+	}()
+
+	// get existing containers too, exited ones are shown as tombstones
+	containers, err := backend.ContainerList(ctx, true)
+	if err != nil {
+		fmt.Printf("Failed to get containers: %v\n", err)
+		close(done)
+		return done
+	}
+	for i := range containers {
+		fmt.Printf("Container is %s: %s\n", containers[i].State, containers[i].ID)
+		addContainer(containers[i].ID)
+	}
+	c.setConnected(true)
+
+	go func() {
+		for {
+			select {
+			case <-time.After(tunables().PingInterval):
+				// signal done if docker server is not available
+				if err := backend.Ping(ctx); err != nil {
+					fmt.Printf("Ping %s server %s failed: %v\n", backend.Name(), host, err)
+					close(done)
+					return
+				}
+				fmt.Printf("Ping %s server %s ok\n", backend.Name(), host)
+				c.dropExpiredTombstones(currentTime())
+			case <-ctx.Done():
+				close(done)
+				return
+			}
+		}
+	}()
+
+	return done
+}
+
+// inspectContainer updates the container from its inspect info, returns the state of the container
+func (c *Collector) inspectContainer(ctx context.Context, backend ContainerBackend, info *ContainerInfo) ContainerState {
+	inspect, err := backend.ContainerInspect(ctx, info.Data.ID)
+	if err != nil {
+		fmt.Printf("Failed to inspect container %s: %v\n", info.Data.ID, err)
+		return ContainerUnknownState
+	}
+
+	info.mutex.Lock()
+	defer info.mutex.Unlock()
+
+	compose_project_dir := inspect.Config.Labels["com.docker.compose.project.working_dir"]
+	container_number := 1
+	if i, err := strconv.Atoi(inspect.Config.Labels["com.docker.compose.container-number"]); err == nil {
+		container_number = i
+	}
+	info.Data.State = ContainerUnknownState
+	if inspect.State != nil {
+		if created, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); err == nil && !created.IsZero() {
+			info.Data.Created = created.Unix()
+		}
+		if finished, err := time.Parse(time.RFC3339Nano, inspect.State.FinishedAt); err == nil && !finished.IsZero() {
+			info.Data.FinishedAt = finished.Unix()
+		}
+		info.Data.State = containerStateFromStatus(inspect.State.Status)
+		info.Data.ExitCode = inspect.State.ExitCode
+		info.Data.OOMKilled = inspect.State.OOMKilled
+	}
+	info.Data.Name = strings.TrimLeft(inspect.Name, "/")
+	info.Data.Image = inspect.Image
+	info.Data.DockerComposeProject = inspect.Config.Labels["com.docker.compose.project"]
+	info.Data.DockerComposeProjectDir = compose_project_dir
+	info.Data.DockerComposeService = inspect.Config.Labels["com.docker.compose.service"]
+	info.Data.DockerComposeContainerNumber = container_number
+	// snapshots of the container data still refer to the previous map
+	info.Data.Labels = maps.Clone(inspect.Config.Labels)
+	info.Data.SetAlternativeName()
+	return info.Data.State
+}
+
+// expiredTombstone returns true if the container exited longer than the retention of exited containers ago
+func expiredTombstone(data ContainerData, now time.Time) bool {
+	retention := tunables().ExitedRetention
+	return retention > 0 && data.State == ContainerExited && data.FinishedAt > 0 &&
+		now.Sub(time.Unix(data.FinishedAt, 0)) > retention
+}
+
+// dropExpiredTombstones drops the containers that exited longer than the retention of exited containers ago
+func (c *Collector) dropExpiredTombstones(now time.Time) {
+	c.containerInfoMutex.Lock()
+	defer c.containerInfoMutex.Unlock()
+	for id, info := range c.containerInfo {
+		info.mutex.RLock()
+		expired, name := expiredTombstone(info.Data, now), info.Data.AlternativeName
+		info.mutex.RUnlock()
+		if expired {
+			fmt.Printf("Dropping container exited more than %s ago: %s (%s)\n", tunables().ExitedRetention, name, id)
+			delete(c.containerInfo, id)
+		}
+	}
+}
+
+// reset drops all followed containers
+func (c *Collector) reset() {
+	c.containerInfoMutex.Lock()
+	defer c.containerInfoMutex.Unlock()
+	c.connected = false
+	c.containerInfo = make(map[string]*ContainerInfo, 0)
+}
+
+// stopSignals are the signals sent to stop a container: SIGINT, SIGQUIT, SIGKILL and SIGTERM
+var stopSignals = []int{2, 3, 9, 15}
+
+// requestedExit returns true if the exit code tells the container terminated by one of the signals sent to it,
+// e.g. by stopping or killing it, or exited successfully after it got stopped, instead of crashing
+func requestedExit(exitCode int, signals []int) bool {
+	if exitCode == 0 {
+		return slices.ContainsFunc(signals, func(signal int) bool {
+			return slices.Contains(stopSignals, signal)
+		})
+	}
+	return slices.ContainsFunc(signals, func(signal int) bool {
+		return exitCode == 128+signal
+	})
+}
+
+// eventNotification creates a notification of a lifecycle event of a container, the container may not be known yet
+func (c *Collector) eventNotification(id string, action string, attributes map[string]string) Notification {
+	data := ContainerData{
+		ID:                   id,
+		Host:                 c.Host(),
+		Name:                 attributes["name"],
+		Image:                attributes["image"],
+		DockerComposeProject: attributes["com.docker.compose.project"],
+		DockerComposeService: attributes["com.docker.compose.service"],
+	}
+	if number, err := strconv.Atoi(attributes["com.docker.compose.container-number"]); err == nil {
+		data.DockerComposeContainerNumber = number
+	}
+	data.SetAlternativeName()
+	if info, ok := c.Container(id); ok {
+		info.mutex.RLock()
+		data.AlternativeName = info.Data.AlternativeName
+		info.mutex.RUnlock()
+	}
+
+	switch action {
+	case "start":
+		return containerNotification(NotifyStart, data, fmt.Sprintf("Container %s started", data.AlternativeName))
+	case "stop":
+		return containerNotification(NotifyStop, data, fmt.Sprintf("Container %s stopped", data.AlternativeName))
+	case "oom":
+		return containerNotification(NotifyOOM, data, fmt.Sprintf("Container %s ran out of memory", data.AlternativeName))
+	default:
+		data.ExitCode, _ = strconv.Atoi(attributes["exitCode"])
+		return containerNotification(NotifyDie, data, fmt.Sprintf("Container %s died with exit code %d", data.AlternativeName, data.ExitCode))
+	}
+}
+
+// getDockerStatsWithRetry follows containers of the runtime and reconnects when runtime got unavailable or switched
+func (c *Collector) getDockerStatsWithRetry(ctx context.Context) {
+	go func() {
+		for {
+			fmt.Printf("Following stats of %s...\n", c.Host())
+			statsCtx, cancel := context.WithCancel(context.Background())
+			done := c.getDockerStats(statsCtx)
+			select {
+			case <-done:
+				retryAfter := tunables().RetryInterval
+				fmt.Printf("Retrying to follow stats of %s in %s...\n", c.Host(), retryAfter)
+				cancel()
+				c.workers.Wait()
+				c.reset()
+				select {
+				case <-time.After(retryAfter):
+				case <-c.switched:
+					fmt.Printf("Switching to %s...\n", c.Host())
+				case <-ctx.Done():
+					return
+				}
+			case <-c.switched:
+				fmt.Printf("Switching to %s...\n", c.Host())
+				// containers of the previous backend must not be added after the reset
+				cancel()
+				c.workers.Wait()
+				c.reset()
+			case <-ctx.Done():
+				cancel()
+				return
+			}
+		}
+	}()
+}
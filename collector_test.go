@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startCollector follows the containers of the backend until the test ended
+func startCollector(t *testing.T, backend *fakeBackend) *Collector {
+	t.Helper()
+	collector := NewCollector("fake", backend.factory())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	collector.getDockerStatsWithRetry(ctx)
+	waitFor(t, "collector to connect", collector.Connected)
+	return collector
+}
+
+// startGlobalCollector starts the collector of the backend as the only collector of the app until the test ended
+func startGlobalCollector(t *testing.T, backend *fakeBackend) *Collector {
+	t.Helper()
+	collector := startCollector(t, backend)
+	collectorMutex.Lock()
+	previous := collectors
+	collectors = []*Collector{collector}
+	collectorMutex.Unlock()
+	t.Cleanup(func() {
+		collectorMutex.Lock()
+		collectors = previous
+		collectorMutex.Unlock()
+	})
+	return collector
+}
+
+// containerData returns the data of the container with given id
+func containerData(collector *Collector, id string) (ContainerData, bool) {
+	for _, data := range collector.ContainerData() {
+		if data.ID == id {
+			return data, true
+		}
+	}
+	return ContainerData{}, false
+}
+
+func containerState(collector *Collector, id string) ContainerState {
+	data, _ := containerData(collector, id)
+	return data.State
+}
+
+func TestCollectorListsContainers(t *testing.T) {
+	backend := newFakeBackend(
+		fakeContainer{ID: "c1", Name: "web", Status: "running", Labels: map[string]string{
+			"com.docker.compose.project":          "shop",
+			"com.docker.compose.service":          "web",
+			"com.docker.compose.container-number": "2",
+		}},
+		fakeContainer{ID: "c2", Name: "job", Status: "exited", ExitCode: 3},
+	)
+	collector := startCollector(t, backend)
+
+	waitFor(t, "containers to be listed", func() bool {
+		return len(collector.ContainerData()) == 2
+	})
+	web, _ := containerData(collector, "c1")
+	if web.State != ContainerRunning || web.AlternativeName != "web-2" || web.DockerComposeProject != "shop" || web.Host != "fake" {
+		t.Errorf("unexpected data of running container: %+v", web)
+	}
+	job, _ := containerData(collector, "c2")
+	if job.State != ContainerExited || job.ExitCode != 3 || job.AlternativeName != "job" {
+		t.Errorf("unexpected data of exited container: %+v", job)
+	}
+}
+
+func TestCollectorFollowsStats(t *testing.T) {
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	collector := startCollector(t, backend)
+	waitFor(t, "container to be listed", func() bool {
+		_, ok := containerData(collector, "c1")
+		return ok
+	})
+
+	backend.sendStats(t, "c1", 100, 1000, 1024)
+	backend.sendStats(t, "c1", 200, 2000, 2048)
+	waitFor(t, "second stats sample", func() bool {
+		data, _ := containerData(collector, "c1")
+		return data.Memory == 2048
+	})
+	data, _ := containerData(collector, "c1")
+	if data.CpuPercent != 10 {
+		t.Errorf("expected cpu usage of 10%%, got %v", data.CpuPercent)
+	}
+	if len(data.MemoryHistory.Samples) != 2 {
+		t.Errorf("expected 2 memory samples, got %d", len(data.MemoryHistory.Samples))
+	}
+}
+
+func TestCollectorFollowsStatsAgainAfterFailure(t *testing.T) {
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	backend.statsErr = errors.New("connection refused")
+	collector := startCollector(t, backend)
+	waitFor(t, "failure to follow stats", func() bool {
+		info, ok := collector.Container("c1")
+		if !ok {
+			return false
+		}
+		info.mutex.RLock()
+		defer info.mutex.RUnlock()
+		return info.Data.State == ContainerRunning && !info.following
+	})
+
+	backend.mutex.Lock()
+	backend.statsErr = nil
+	backend.mutex.Unlock()
+	backend.emit(t, "c1", "start", map[string]string{"name": "web"})
+	backend.sendStats(t, "c1", 100, 1000, 1024)
+	waitFor(t, "stats of container", func() bool {
+		data, _ := containerData(collector, "c1")
+		return data.Memory == 1024
+	})
+}
+
+func TestCollectorSnapshotsDontShareHistory(t *testing.T) {
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	collector := startCollector(t, backend)
+	backend.sendStats(t, "c1", 100, 1000, 1024)
+	waitFor(t, "first stats sample", func() bool {
+		data, _ := containerData(collector, "c1")
+		return len(data.MemoryHistory.Samples) == 1
+	})
+	snapshot, _ := containerData(collector, "c1")
+
+	// the second sample is aggregated into the same buckets of the tiers
+	backend.sendStats(t, "c1", 200, 2000, 2048)
+	waitFor(t, "second stats sample", func() bool {
+		data, _ := containerData(collector, "c1")
+		return len(data.MemoryHistory.Samples) == 2
+	})
+	if len(snapshot.MemoryHistory.Samples) != 1 {
+		t.Errorf("expected 1 sample in the snapshot, got %d", len(snapshot.MemoryHistory.Samples))
+	}
+	for _, tier := range snapshot.MemoryHistory.Tiers {
+		if len(tier.Buckets) != 1 || tier.Buckets[0].count != 1 || tier.Buckets[0].max != 1024 {
+			t.Errorf("expected the bucket of the snapshot to keep the first sample only, got %+v", tier.Buckets)
+		}
+	}
+}
+
+func TestCollectorFollowsEvents(t *testing.T) {
+	backend := newFakeBackend()
+	collector := startCollector(t, backend)
+
+	backend.addContainer(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	backend.emit(t, "c1", "start", map[string]string{"name": "web"})
+	waitFor(t, "started container to be followed", func() bool {
+		return containerState(collector, "c1") == ContainerRunning
+	})
+
+	backend.emit(t, "c1", "pause", nil)
+	waitFor(t, "container to be paused", func() bool {
+		return containerState(collector, "c1") == ContainerPaused
+	})
+	backend.emit(t, "c1", "unpause", nil)
+	waitFor(t, "container to be unpaused", func() bool {
+		return containerState(collector, "c1") == ContainerRunning
+	})
+
+	backend.setStatus("c1", "exited", 1)
+	backend.emit(t, "c1", "die", map[string]string{"name": "web", "exitCode": "1"})
+	waitFor(t, "container to be kept as tombstone", func() bool {
+		return containerState(collector, "c1") == ContainerExited
+	})
+
+	backend.emit(t, "c1", "destroy", nil)
+	waitFor(t, "removed container to be dropped", func() bool {
+		_, ok := containerData(collector, "c1")
+		return !ok
+	})
+}
+
+func TestCollectorSnapshotsKeepLabelsOfPreviousInspect(t *testing.T) {
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running", Labels: map[string]string{"version": "1"}})
+	collector := startCollector(t, backend)
+	waitFor(t, "container to be listed", func() bool {
+		return containerState(collector, "c1") == ContainerRunning
+	})
+	snapshot, _ := containerData(collector, "c1")
+
+	// a start event inspects the container again
+	backend.setLabels("c1", map[string]string{"version": "2", "extra": "yes"})
+	backend.emit(t, "c1", "start", map[string]string{"name": "web"})
+	waitFor(t, "labels of the new inspect", func() bool {
+		data, _ := containerData(collector, "c1")
+		return data.Labels["version"] == "2"
+	})
+	if len(snapshot.Labels) != 1 || snapshot.Labels["version"] != "1" {
+		t.Errorf("expected the snapshot to keep the labels of the previous inspect, got %v", snapshot.Labels)
+	}
+}
+
+func TestCollectorControlsContainers(t *testing.T) {
+	backend := newFakeBackend(
+		fakeContainer{ID: "c1", Name: "web", Status: "running"},
+		fakeContainer{ID: "c2", Name: "job", Status: "exited"},
+	)
+	collector := startCollector(t, backend)
+	waitFor(t, "containers to be listed", func() bool {
+		return len(collector.ContainerData()) == 2
+	})
+
+	web, _ := collector.Container("c1")
+	web.Kill("SIGHUP")
+	web.Stop()
+	job, _ := collector.Container("c2")
+	job.Start()
+	backend.setStatus("c2", "running", 0)
+	backend.emit(t, "c2", "start", map[string]string{"name": "job"})
+	waitFor(t, "container to be started", func() bool {
+		return containerState(collector, "c2") == ContainerRunning
+	})
+	backend.setStatus("c2", "exited", 1)
+	backend.emit(t, "c2", "die", map[string]string{"name": "job", "exitCode": "1"})
+	waitFor(t, "container to exit again", func() bool {
+		return containerState(collector, "c2") == ContainerExited
+	})
+	job.Restart()
+
+	expected := []string{"kill c1 SIGHUP", "stop c1", "start c2", "restart c2"}
+	if calls := backend.Calls(); !slices.Equal(calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestCollectorSwitchesBackend(t *testing.T) {
+	previous := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	collector := startCollector(t, previous)
+	waitFor(t, "container of previous backend", func() bool {
+		_, ok := containerData(collector, "c1")
+		return ok
+	})
+
+	next := newFakeBackend(fakeContainer{ID: "c2", Name: "db", Status: "running"})
+	collector.SwitchBackend("next", next.factory())
+	waitFor(t, "container of next backend", func() bool {
+		_, ok := containerData(collector, "c2")
+		return ok
+	})
+	if _, ok := containerData(collector, "c1"); ok {
+		t.Errorf("container of previous backend is still followed")
+	}
+	if host := collector.Host(); host != "next" {
+		t.Errorf("expected host to be renamed to next, got %s", host)
+	}
+	data, _ := containerData(collector, "c2")
+	if data.Host != "next" {
+		t.Errorf("expected container of host next, got %s", data.Host)
+	}
+	waitFor(t, "previous backend to be closed", func() bool {
+		previous.mutex.Lock()
+		defer previous.mutex.Unlock()
+		return previous.closed == 1
+	})
+}
+
+func TestCollectorSkipsCounterDeltaOfFirstReading(t *testing.T) {
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	collector := startCollector(t, backend)
+	waitFor(t, "container to be listed", func() bool {
+		_, ok := containerData(collector, "c1")
+		return ok
+	})
+
+	backend.sendStats(t, "c1", 100, 1000, 1024)
+	waitFor(t, "first stats sample", func() bool {
+		data, _ := containerData(collector, "c1")
+		return data.LastUpdated > 0
+	})
+	data, _ := containerData(collector, "c1")
+	if len(data.NetworkRxHistory.Samples) != 0 || len(data.BlockReadHistory.Samples) != 0 {
+		t.Errorf("expected no counter samples of the first reading, got %v and %v", data.NetworkRxHistory.Samples, data.BlockReadHistory.Samples)
+	}
+
+	backend.sendStats(t, "c1", 200, 2000, 2048)
+	waitFor(t, "second stats sample", func() bool {
+		data, _ := containerData(collector, "c1")
+		return data.Memory == 2048
+	})
+	data, _ = containerData(collector, "c1")
+	if len(data.NetworkRxHistory.Samples) != 1 || len(data.BlockReadHistory.Samples) != 1 {
+		t.Errorf("expected a counter sample of the second reading, got %v and %v", data.NetworkRxHistory.Samples, data.BlockReadHistory.Samples)
+	}
+}
+
+func TestCollectorDropsExpiredTombstones(t *testing.T) {
+	now := time.Now()
+	backend := newFakeBackend(
+		fakeContainer{ID: "c1", Name: "web", Status: "running"},
+		fakeContainer{ID: "c2", Name: "old", Status: "exited", Finished: now.Add(-2 * ExitedRetention)},
+		fakeContainer{ID: "c3", Name: "recent", Status: "exited", Finished: now.Add(-time.Minute)},
+	)
+	collector := startCollector(t, backend)
+	waitFor(t, "containers to be listed", func() bool {
+		_, ok := containerData(collector, "c3")
+		return ok
+	})
+	if _, ok := containerData(collector, "c2"); ok {
+		t.Errorf("expected container exited before retention to be dropped")
+	}
+
+	collector.dropExpiredTombstones(now.Add(ExitedRetention))
+	if _, ok := containerData(collector, "c3"); ok {
+		t.Errorf("expected tombstone to be dropped after retention")
+	}
+	if _, ok := containerData(collector, "c1"); !ok {
+		t.Errorf("expected running container to be kept")
+	}
+}
+
+// notificationRecorder is a notification channel keeping all notifications
+type notificationRecorder struct {
+	mutex         sync.Mutex
+	notifications []Notification
+}
+
+// recordNotifications records the notifications sent until the test ended
+func recordNotifications(t *testing.T) *notificationRecorder {
+	recorder := &notificationRecorder{}
+	addNotificationChannel("test", recorder)
+	t.Cleanup(func() {
+		removeNotificationChannel("test", recorder)
+	})
+	return recorder
+}
+
+func (r *notificationRecorder) Notify(notification Notification) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.notifications = append(r.notifications, notification)
+}
+
+func (r *notificationRecorder) Close() {}
+
+// Messages returns the messages of the notifications of given kind
+func (r *notificationRecorder) Messages(kind string) []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var messages []string
+	for _, notification := range r.notifications {
+		if notification.Kind == kind {
+			messages = append(messages, notification.Message)
+		}
+	}
+	return messages
+}
+
+func TestCollectorNotifiesHealthChanges(t *testing.T) {
+	notifications := recordNotifications(t)
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running", Health: "healthy"})
+	collector := startCollector(t, backend)
+	waitFor(t, "container to be listed", func() bool {
+		_, ok := containerData(collector, "c1")
+		return ok
+	})
+
+	var cpu uint64
+	health := func(status string) {
+		backend.setHealth("c1", status)
+		cpu += 100
+		backend.sendStats(t, "c1", cpu, cpu*10, 1024)
+		waitFor(t, "health "+status, func() bool {
+			data, _ := containerData(collector, "c1")
+			return data.HealthStatus.String() == status
+		})
+	}
+	health("healthy")
+	health("starting")
+	health("healthy")
+	health("unhealthy")
+
+	// restarting the health check of a healthy container is not notified
+	expected := []string{"Container web became unhealthy"}
+	if messages := notifications.Messages(NotifyHealth); !slices.Equal(messages, expected) {
+		t.Errorf("expected health notifications %v, got %v", expected, messages)
+	}
+}
+
+func TestCollectorNotifiesDieOfCrashedContainersOnly(t *testing.T) {
+	notifications := recordNotifications(t)
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	startCollector(t, backend)
+
+	exit := func(exitCode string, signals ...string) {
+		for _, signal := range signals {
+			backend.emit(t, "c1", "kill", map[string]string{"name": "web", "signal": signal})
+		}
+		backend.emit(t, "c1", "die", map[string]string{"name": "web", "exitCode": exitCode})
+		backend.emit(t, "c1", "start", map[string]string{"name": "web"})
+	}
+	exit("143", "15")      // stopped
+	exit("137", "15", "9") // stopped after timeout
+	exit("130", "2")       // interrupted
+	exit("1", "1")         // crashed after reloading by SIGHUP
+	exit("137")            // killed by the OOM killer
+	exit("2")              // crashed
+	waitFor(t, "all start notifications", func() bool {
+		return len(notifications.Messages(NotifyStart)) == 6
+	})
+
+	expected := []string{
+		"Container web died with exit code 1",
+		"Container web died with exit code 137",
+		"Container web died with exit code 2",
+	}
+	if messages := notifications.Messages(NotifyDie); !slices.Equal(messages, expected) {
+		t.Errorf("expected die notifications %v, got %v", expected, messages)
+	}
+}
+
+func TestRequestedExit(t *testing.T) {
+	tests := []struct {
+		exitCode  int
+		signals   []int
+		requested bool
+	}{
+		{143, []int{15}, true},    // stopped
+		{137, []int{15, 9}, true}, // stopped after timeout
+		{0, []int{15}, true},      // exited gracefully when stopped
+		{129, []int{1}, true},     // terminated by SIGHUP
+		{137, []int{1}, false},    // killed by the OOM killer after reloading by SIGHUP
+		{143, []int{1}, false},    // terminated on its own after reloading by SIGHUP
+		{0, []int{1}, false},      // exited on its own after reloading by SIGHUP
+		{1, []int{15}, false},     // failed to stop
+		{137, nil, false},         // killed by the OOM killer
+		{0, nil, false},           // exited on its own
+	}
+	for _, test := range tests {
+		if actual := requestedExit(test.exitCode, test.signals); actual != test.requested {
+			t.Errorf("requestedExit(%d, %v): expected %v, got %v", test.exitCode, test.signals, test.requested, actual)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConfigPollInterval is the interval the config file is checked for modifications in
+const ConfigPollInterval = 2 * time.Second
+
+// HexColor is a color given as "#rrggbb" or "#rrggbbaa"
+type HexColor color.RGBA
+
+func (c HexColor) String() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func (c HexColor) MarshalYAML() (any, error) {
+	return c.String(), nil
+}
+
+func (c *HexColor) UnmarshalYAML(node *yaml.Node) error {
+	value := strings.TrimPrefix(node.Value, "#")
+	var rgba color.RGBA
+	switch len(value) {
+	case 6:
+		rgba.A = 255
+		if _, err := fmt.Sscanf(value, "%02x%02x%02x", &rgba.R, &rgba.G, &rgba.B); err != nil {
+			return fmt.Errorf("line %d: invalid color %q, expected #rrggbb or #rrggbbaa", node.Line, node.Value)
+		}
+	case 8:
+		if _, err := fmt.Sscanf(value, "%02x%02x%02x%02x", &rgba.R, &rgba.G, &rgba.B, &rgba.A); err != nil {
+			return fmt.Errorf("line %d: invalid color %q, expected #rrggbb or #rrggbbaa", node.Line, node.Value)
+		}
+	default:
+		return fmt.Errorf("line %d: invalid color %q, expected #rrggbb or #rrggbbaa", node.Line, node.Value)
+	}
+	*c = HexColor(rgba)
+	return nil
+}
+
+// Config holds the tunables of the HUD, it is read from a YAML file and command-line flags override its values
+type Config struct {
+	RecentDuration    time.Duration `yaml:"recent_duration"`
+	MaxHistorySamples int           `yaml:"max_history_samples"`
+	RefreshInterval   time.Duration `yaml:"refresh_interval"`
+	PingInterval      time.Duration `yaml:"ping_interval"`
+	RetryInterval     time.Duration `yaml:"retry_interval"`
+	ExitedRetention   time.Duration `yaml:"exited_retention"`
+
+	IconSize      int `yaml:"icon_size"`
+	TooltipWidth  int `yaml:"tooltip_width"`
+	TooltipHeight int `yaml:"tooltip_height"`
+	WindowWidth   int `yaml:"window_width"`
+	WindowHeight  int `yaml:"window_height"`
+
+	LabelColor           HexColor `yaml:"label_color"`
+	CpuBarColor          HexColor `yaml:"cpu_bar_color"`
+	CpuThrottledBarColor HexColor `yaml:"cpu_throttled_bar_color"`
+	MemBarColor          HexColor `yaml:"mem_bar_color"`
+
+	// AlertRules are the rules of the alert engine, see AlertRule
+	AlertRules []string `yaml:"alert_rules"`
+	// Webhooks receive notifications about container lifecycle events and alerts
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+	// DesktopNotifications shows notifications about health changes and crashed containers on the desktop
+	DesktopNotifications bool `yaml:"desktop_notifications"`
+	// MQTT publishes the state of containers to a MQTT broker
+	MQTT MQTTConfig `yaml:"mqtt"`
+	// OTLP pushes container metrics to an OpenTelemetry collector
+	OTLP OTLPConfig `yaml:"otlp"`
+}
+
+// configKeys are the keys of the config file with their description that can be overridden by a flag
+var configKeys = []struct {
+	key   string
+	usage string
+}{
+	{"recent_duration", "initial time window of history plots, e.g. 5m"},
+	{"max_history_samples", "max number of samples kept per metric and resolution"},
+	{"refresh_interval", "interval the UI is refreshed in, e.g. 1s"},
+	{"ping_interval", "interval the container runtime is pinged in"},
+	{"retry_interval", "delay before reconnecting to an unavailable container runtime"},
+	{"exited_retention", "duration exited containers are shown after they finished, 0 to show them until removed"},
+	{"icon_size", "size of icons in pixels"},
+	{"tooltip_width", "width of history plot tooltips in pixels"},
+	{"tooltip_height", "height of history plot tooltips in pixels"},
+	{"window_width", "initial width of the window in pixels"},
+	{"window_height", "initial height of the window in pixels"},
+	{"label_color", "color of labels as #rrggbb or #rrggbbaa"},
+	{"cpu_bar_color", "color of the cpu bar-graph"},
+	{"cpu_throttled_bar_color", "color of the cpu throttling bar-graph"},
+	{"mem_bar_color", "color of the memory bar-graph"},
+	{"desktop_notifications", "show desktop notifications about health changes and crashed containers"},
+}
+
+var (
+	configOverrides      = make(map[string]string) // values of config flags given on the command-line by key
+	configOverridesMutex = sync.Mutex{}
+)
+
+// builtinConfig returns the config of the built-in defaults of the tunables
+func builtinConfig() Config {
+	return Config{
+		RecentDuration:       RecentDuration,
+		MaxHistorySamples:    MaxHistorySamples,
+		RefreshInterval:      RefreshInterval,
+		PingInterval:         PingInterval,
+		RetryInterval:        RetryInterval,
+		ExitedRetention:      ExitedRetention,
+		IconSize:             int(IconSize),
+		TooltipWidth:         TooltipWidth,
+		TooltipHeight:        TooltipHeight,
+		WindowWidth:          WindowWidth,
+		WindowHeight:         WindowHeight,
+		LabelColor:           HexColor(LabelColor),
+		CpuBarColor:          HexColor(CpuBarColor),
+		CpuThrottledBarColor: HexColor(CpuThrottledBarColor),
+		MemBarColor:          HexColor(MemBarColor),
+	}
+}
+
+var (
+	// defaultConfig holds the built-in values of the tunables
+	defaultConfig = builtinConfig()
+	// appliedConfig is the config applied last, it is replaced as a whole on reload
+	appliedConfig atomic.Pointer[Config]
+)
+
+// tunables returns the config applied last or the built-in defaults, it is safe to be called from any goroutine.
+// The returned config must not be modified.
+func tunables() *Config {
+	if config := appliedConfig.Load(); config != nil {
+		return config
+	}
+	return &defaultConfig
+}
+
+// Apply sets the tunables to the values of the config
+func (c Config) Apply() {
+	appliedConfig.Store(&c)
+
+	rules := make([]AlertRule, 0, len(c.AlertRules))
+	for _, text := range c.AlertRules {
+		if rule, err := ParseAlertRule(text); err == nil {
+			rules = append(rules, rule)
+		}
+	}
+	alertEngine.SetRules(rules)
+
+	setWebhookConfigs(c.Webhooks)
+	setDesktopNotifications(c.DesktopNotifications)
+
+	if replaying {
+		c.MQTT, c.OTLP = MQTTConfig{}, OTLPConfig{}
+	}
+	setMQTTConfig(c.MQTT)
+	setOTLPConfig(c.OTLP)
+}
+
+// Validate returns an error listing all invalid values
+func (c Config) Validate() error {
+	var errs []error
+	atLeast := func(key string, value int, min int) {
+		if value < min {
+			errs = append(errs, fmt.Errorf("%s must be at least %d, got %d", key, min, value))
+		}
+	}
+	atLeastDuration := func(key string, value time.Duration, min time.Duration) {
+		if value < min {
+			errs = append(errs, fmt.Errorf("%s must be at least %s, got %s", key, min, value))
+		}
+	}
+	atLeastDuration("recent_duration", c.RecentDuration, MinTimeRange)
+	atLeast("max_history_samples", c.MaxHistorySamples, 16)
+	atLeastDuration("refresh_interval", c.RefreshInterval, 100*time.Millisecond)
+	atLeastDuration("ping_interval", c.PingInterval, time.Second)
+	atLeastDuration("retry_interval", c.RetryInterval, time.Second)
+	atLeastDuration("exited_retention", c.ExitedRetention, 0)
+	atLeast("icon_size", c.IconSize, 8)
+	atLeast("tooltip_width", c.TooltipWidth, 100)
+	atLeast("tooltip_height", c.TooltipHeight, 100)
+	atLeast("window_width", c.WindowWidth, 200)
+	atLeast("window_height", c.WindowHeight, 200)
+	for idx, text := range c.AlertRules {
+		if _, err := ParseAlertRule(text); err != nil {
+			errs = append(errs, fmt.Errorf("alert_rules[%d]: %v", idx, err))
+		}
+	}
+	for idx, webhook := range c.Webhooks {
+		if err := webhook.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("webhooks[%d]: %v", idx, err))
+		}
+	}
+	if err := c.MQTT.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("mqtt: %v", err))
+	}
+	if err := c.OTLP.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("otlp: %v", err))
+	}
+	return errors.Join(errs...)
+}
+
+// defaultConfigPath returns the path of the config file in the XDG config dir
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "container-hud", "config.yaml")
+}
+
+// decodeConfig merges the YAML document into the config, unknown keys are rejected
+func decodeConfig(data []byte, config *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// overrideConfig sets a single key of the config to given value, the value is parsed like in the config file
+func overrideConfig(config *Config, key string, value string) error {
+	node := yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: key},
+			{Kind: yaml.ScalarNode, Value: value},
+		},
+	}
+	return node.Decode(config)
+}
+
+// LoadConfig reads the config file on top of the defaults and applies the values given by flags.
+// A missing config file is not an error.
+func LoadConfig(path string) (Config, error) {
+	config := defaultConfig
+	if len(path) > 0 {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return config, err
+		}
+		if err := decodeConfig(data, &config); err != nil {
+			return config, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	configOverridesMutex.Lock()
+	defer configOverridesMutex.Unlock()
+	for key, value := range configOverrides {
+		if err := overrideConfig(&config, key, value); err != nil {
+			return config, fmt.Errorf("flag -%s: %v", configFlagName(key), err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		if len(path) > 0 {
+			return config, fmt.Errorf("invalid config %s:\n%v", path, err)
+		}
+		return config, fmt.Errorf("invalid config:\n%v", err)
+	}
+	return config, nil
+}
+
+// WatchConfig reloads and applies the config file whenever its modification time changes until done is closed.
+// An invalid config is reported and the previous values are kept.
+func WatchConfig(path string, done <-chan struct{}) {
+	modTime := func() time.Time {
+		if info, err := os.Stat(path); err == nil {
+			return info.ModTime()
+		}
+		return time.Time{}
+	}
+	lastModTime := modTime()
+	for {
+		select {
+		case <-time.After(ConfigPollInterval):
+		case <-done:
+			return
+		}
+		if current := modTime(); !current.Equal(lastModTime) {
+			lastModTime = current
+			config, err := LoadConfig(path)
+			if err != nil {
+				fmt.Printf("Failed to reload config: %v\n", err)
+				continue
+			}
+			fmt.Printf("Reloaded config %s\n", path)
+			config.Apply()
+		}
+	}
+}
+
+// configFlag is a command-line flag overriding a key of the config file
+type configFlag struct {
+	key string
+}
+
+func (f *configFlag) String() string {
+	configOverridesMutex.Lock()
+	defer configOverridesMutex.Unlock()
+	return configOverrides[f.key]
+}
+
+func (f *configFlag) Set(value string) error {
+	config := defaultConfig
+	if err := overrideConfig(&config, f.key, value); err != nil {
+		return err
+	}
+	configOverridesMutex.Lock()
+	defer configOverridesMutex.Unlock()
+	configOverrides[f.key] = value
+	return nil
+}
+
+func configFlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// registerConfigFlags adds a command-line flag for every key of the config file
+func registerConfigFlags() {
+	for _, k := range configKeys {
+		flag.Var(&configFlag{key: k.key}, configFlagName(k.key), fmt.Sprintf("%s, overrides %q of the config file", k.usage, k.key))
+	}
+}
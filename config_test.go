@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyConfigWhileCollecting(t *testing.T) {
+	t.Cleanup(func() {
+		appliedConfig.Store(nil)
+	})
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "running"})
+	collector := startCollector(t, backend)
+	waitFor(t, "container to be listed", func() bool {
+		_, ok := containerData(collector, "c1")
+		return ok
+	})
+
+	// reloads replace the tunables while the collector reads them
+	for i := 0; i < 20; i++ {
+		config := defaultConfig
+		config.MaxHistorySamples = 16 + i
+		config.ExitedRetention = time.Duration(i) * time.Hour
+		config.Apply()
+		backend.sendStats(t, "c1", uint64(100*(i+1)), uint64(1000*(i+1)), 1024)
+	}
+	waitFor(t, "stats of all samples", func() bool {
+		data, _ := containerData(collector, "c1")
+		return len(data.MemoryHistory.Samples) == 20
+	})
+	if samples := tunables().MaxHistorySamples; samples != 35 {
+		t.Errorf("expected max history samples of the config applied last, got %d", samples)
+	}
+}
@@ -0,0 +1,154 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/godbus/dbus/v5"
+	"slices"
+	"sync"
+)
+
+const (
+	desktopNotificationsName      = "org.freedesktop.Notifications"
+	desktopNotificationsPath      = "/org/freedesktop/Notifications"
+	desktopNotificationsInterface = "org.freedesktop.Notifications"
+
+	// desktopRestartAction is the key of the action button restarting the container of a notification
+	desktopRestartAction = "restart"
+)
+
+// DesktopNotifier shows notifications via the freedesktop notification service on the session D-Bus
+type DesktopNotifier struct {
+	conn   *dbus.Conn
+	queue  chan Notification
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	containers      map[uint32]string // container ids by id of notifications shown
+	containersMutex sync.Mutex
+}
+
+var _ NotificationChannel = &DesktopNotifier{}
+
+// NewDesktopNotifier connects to the session D-Bus and starts showing queued notifications
+func NewDesktopNotifier() (*DesktopNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %v", err)
+	}
+	err = conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(desktopNotificationsPath),
+		dbus.WithMatchInterface(desktopNotificationsInterface),
+	)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to notification signals: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &DesktopNotifier{
+		conn:       conn,
+		queue:      make(chan Notification, WebhookQueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		containers: make(map[uint32]string),
+	}
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+	go n.deliver()
+	go n.handleSignals(signals)
+	return n, nil
+}
+
+// desktopNotificationKinds are the kinds of notifications shown on the desktop
+var desktopNotificationKinds = []string{NotifyHealth, NotifyDie, NotifyOOM}
+
+func (n *DesktopNotifier) Notify(notification Notification) {
+	if !slices.Contains(desktopNotificationKinds, notification.Kind) {
+		return
+	}
+	select {
+	case n.queue <- notification:
+	default:
+		fmt.Printf("Dropped %s desktop notification, queue is full\n", notification.Kind)
+	}
+}
+
+func (n *DesktopNotifier) Close() {
+	n.cancel()
+	_ = n.conn.Close()
+}
+
+func (n *DesktopNotifier) deliver() {
+	for {
+		select {
+		case notification := <-n.queue:
+			if err := n.show(notification); err != nil {
+				fmt.Printf("Failed to show desktop notification: %v\n", err)
+			}
+		case <-n.ctx.Done():
+			return
+		}
+	}
+}
+
+// show sends the notification to the notification service with a button to restart the container
+func (n *DesktopNotifier) show(notification Notification) error {
+	summary := notification.ContainerName
+	if len(notification.Host) > 0 {
+		summary = fmt.Sprintf("%s (%s)", summary, notification.Host)
+	}
+	urgency := byte(1) // normal
+	if notification.Kind != NotifyHealth || notification.HealthStatus == Unhealthy.String() {
+		urgency = 2 // critical
+	}
+	actions := []string{desktopRestartAction, "Restart container"}
+	hints := map[string]dbus.Variant{
+		"urgency":       dbus.MakeVariant(urgency),
+		"desktop-entry": dbus.MakeVariant("container-hud"),
+	}
+
+	var id uint32
+	err := n.conn.Object(desktopNotificationsName, desktopNotificationsPath).CallWithContext(n.ctx,
+		desktopNotificationsInterface+".Notify", 0,
+		"Container HUD", uint32(0), "", summary, notification.Message, actions, hints, int32(-1),
+	).Store(&id)
+	if err != nil {
+		return err
+	}
+	n.containersMutex.Lock()
+	defer n.containersMutex.Unlock()
+	n.containers[id] = notification.ContainerID
+	return nil
+}
+
+// handleSignals restarts the container of a notification whose restart button got clicked
+func (n *DesktopNotifier) handleSignals(signals <-chan *dbus.Signal) {
+	for signal := range signals {
+		if len(signal.Body) == 0 {
+			continue
+		}
+		id, ok := signal.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+		switch signal.Name {
+		case desktopNotificationsInterface + ".ActionInvoked":
+			n.containersMutex.Lock()
+			containerID, known := n.containers[id]
+			n.containersMutex.Unlock()
+			if !known || len(signal.Body) < 2 {
+				continue
+			}
+			if action, _ := signal.Body[1].(string); action == desktopRestartAction {
+				fmt.Printf("Restarting container %s from desktop notification\n", containerID)
+				go restartContainer(containerID)
+			}
+		case desktopNotificationsInterface + ".NotificationClosed":
+			n.containersMutex.Lock()
+			delete(n.containers, id)
+			n.containersMutex.Unlock()
+		}
+	}
+}
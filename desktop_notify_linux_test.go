@@ -0,0 +1,153 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"github.com/godbus/dbus/v5"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// startSessionBus runs a private dbus-daemon as session bus until the test ended, the test is skipped without dbus-daemon
+func startSessionBus(t *testing.T) {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	address := "unix:path=" + filepath.Join(t.TempDir(), "bus")
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address", "--address="+address)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	// the daemon prints its address once it accepts connections
+	printed, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read address of dbus-daemon: %v", err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(printed))
+}
+
+// shownNotification is a notification received by the fakeNotificationService
+type shownNotification struct {
+	Summary string
+	Body    string
+	Actions []string
+	Urgency byte
+}
+
+// fakeNotificationService is a freedesktop notification service on the session bus recording the notifications shown
+type fakeNotificationService struct {
+	conn  *dbus.Conn
+	mutex sync.Mutex
+	shown []shownNotification
+}
+
+func startNotificationService(t *testing.T) *fakeNotificationService {
+	t.Helper()
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	s := &fakeNotificationService{conn: conn}
+	if err := conn.Export(s, desktopNotificationsPath, desktopNotificationsInterface); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(desktopNotificationsName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own name %s: %v", desktopNotificationsName, err)
+	}
+	return s
+}
+
+// Notify is called by the notifier via D-Bus, returns the id of the notification
+func (s *fakeNotificationService) Notify(app string, replacesID uint32, icon string, summary string, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	urgency, _ := hints["urgency"].Value().(byte)
+	s.shown = append(s.shown, shownNotification{Summary: summary, Body: body, Actions: actions, Urgency: urgency})
+	return uint32(len(s.shown)), nil
+}
+
+func (s *fakeNotificationService) Shown() []shownNotification {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.shown)
+}
+
+func TestDesktopNotifierShowsNotifications(t *testing.T) {
+	startSessionBus(t)
+	service := startNotificationService(t)
+	notifier, err := NewDesktopNotifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(notifier.Close)
+
+	notifier.Notify(Notification{Kind: NotifyStart, Host: "local", ContainerID: "c1", ContainerName: "web", Message: "Container web started"})
+	notifier.Notify(Notification{Kind: NotifyHealth, Host: "local", ContainerID: "c1", ContainerName: "web",
+		Message: "Container web became healthy", HealthStatus: Healthy.String()})
+	notifier.Notify(Notification{Kind: NotifyDie, Host: "local", ContainerID: "c1", ContainerName: "web", Message: "Container web died with exit code 1"})
+	waitFor(t, "notifications to be shown", func() bool {
+		return len(service.Shown()) == 2
+	})
+
+	shown := service.Shown()
+	expected := []shownNotification{
+		{Summary: "web (local)", Body: "Container web became healthy", Actions: []string{desktopRestartAction, "Restart container"}, Urgency: 1},
+		{Summary: "web (local)", Body: "Container web died with exit code 1", Actions: []string{desktopRestartAction, "Restart container"}, Urgency: 2},
+	}
+	for i := range expected {
+		if shown[i].Summary != expected[i].Summary || shown[i].Body != expected[i].Body ||
+			!slices.Equal(shown[i].Actions, expected[i].Actions) || shown[i].Urgency != expected[i].Urgency {
+			t.Errorf("expected notification %+v, got %+v", expected[i], shown[i])
+		}
+	}
+}
+
+func TestDesktopNotifierRestartsContainerOfNotification(t *testing.T) {
+	startSessionBus(t)
+	service := startNotificationService(t)
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "web", Status: "exited", ExitCode: 1})
+	collector := startGlobalCollector(t, backend)
+	waitFor(t, "container to be listed", func() bool {
+		return containerState(collector, "c1") == ContainerExited
+	})
+
+	notifier, err := NewDesktopNotifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(notifier.Close)
+	notifier.Notify(Notification{Kind: NotifyDie, ContainerID: "c1", ContainerName: "web", Message: "Container web died with exit code 1"})
+	waitFor(t, "notification to be shown", func() bool {
+		notifier.containersMutex.Lock()
+		defer notifier.containersMutex.Unlock()
+		return len(notifier.containers) == 1
+	})
+
+	// the user clicks the restart button of the notification
+	if err := service.conn.Emit(desktopNotificationsPath, desktopNotificationsInterface+".ActionInvoked", uint32(1), desktopRestartAction); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "container to be restarted", func() bool {
+		return slices.Contains(backend.Calls(), "restart c1")
+	})
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+)
+
+// DesktopNotifier is only supported on Linux
+type DesktopNotifier struct{}
+
+var _ NotificationChannel = &DesktopNotifier{}
+
+// NewDesktopNotifier returns an error, desktop notifications require the session D-Bus of Linux
+func NewDesktopNotifier() (*DesktopNotifier, error) {
+	return nil, errors.New("desktop notifications are only supported on Linux")
+}
+
+func (n *DesktopNotifier) Notify(notification Notification) {}
+
+func (n *DesktopNotifier) Close() {}
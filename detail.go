@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"github.com/AllenDang/giu"
+	"golang.design/x/clipboard"
+	"time"
+)
+
+const (
+	DetailChartColumns = 3
+	DetailChartMinSize = 200
+)
+
+// renderContainerDetail shows inspect info, actions and large history charts of a single container
+func (a *App) renderContainerDetail(data ContainerData) giu.Widget {
+	minXAxis, maxXAxis := a.timeWindow()
+	charts := []func(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget{
+		cpuOnlyHistoryPlot,
+		cpuThrottledHistoryPlot,
+		memoryHistoryPlot,
+		networkHistoryPlot,
+		blockIOHistoryPlot,
+		pidsHistoryPlot,
+	}
+
+	return giu.Child().Layout(
+		giu.Row(
+			giu.Button("< Back").OnClick(func() {
+				a.containerIdDetail = ""
+			}),
+			conditionalButton(data.State == ContainerExited || data.State == ContainerCreated, a.startTexture, "Start container", func() {
+				go a.startContainer(data.ID)
+			}),
+			conditionalButton(data.State == ContainerRunning, a.restartTexture, "Restart container", func() {
+				go a.restartContainer(data.ID)
+			}),
+			conditionalButton(data.State == ContainerRunning, a.stopTexture, "Stop container", func() {
+				go a.stopContainer(data.ID)
+			}),
+			a.pauseButton(data),
+			a.killButton(data),
+			conditionalTexture(data.HealthStatus == UnknownHealth, a.unknownTexture, "Unknown container health status"),
+			conditionalTexture(data.HealthStatus == StartingHealth, a.unknownTexture, "Container health check is starting"),
+			conditionalTexture(data.HealthStatus == Unhealthy, a.unhealthyTexture, "Container is unhealthy"),
+			conditionalTexture(data.HealthStatus == Healthy, a.healthyTexture, "Container is healthy"),
+			giu.Label(data.AlternativeName),
+		),
+		giu.Separator(),
+		detailInfo("ID", data.ID),
+		detailInfo("Name", data.Name),
+		detailInfo("Image", data.Image),
+		detailInfo("Host", data.Host),
+		detailInfo("State", fmt.Sprintf("%s, health %s", data.State, data.HealthStatus)),
+		giu.Condition(data.State.IsTombstone(),
+			giu.Layout{detailInfo("Exit", exitSummary(data, a.now()))},
+			giu.Layout{detailInfo("Uptime", a.now().Sub(time.Unix(data.Created, 0)).Round(time.Second).String())},
+		),
+		giu.Condition(len(data.DockerComposeProject) > 0,
+			giu.Layout{
+				detailInfo("Compose", fmt.Sprintf("project %s, service %s #%d", data.DockerComposeProject, data.DockerComposeService, data.DockerComposeContainerNumber)),
+				detailInfo("Project", data.DockerComposeProjectDir),
+			},
+			nil,
+		),
+		giu.Separator(),
+		giu.Custom(func() {
+			w, _ := giu.GetAvailableRegion()
+			spacingX, _ := giu.GetItemSpacing()
+			width := max(DetailChartMinSize, int((w-spacingX*float32(DetailChartColumns-1))/DetailChartColumns))
+			height := width * 2 / 3
+			for i, chart := range charts {
+				if i%DetailChartColumns > 0 {
+					giu.SameLine()
+				}
+				chart(data, minXAxis, maxXAxis, width, height).Build()
+				a.timeWindowControl(true).Build()
+			}
+		}),
+		giu.Separator(),
+		giu.TreeNode("Processes").Layout(
+			giu.Custom(func() {
+				a.processPanel(data).Build()
+			}),
+		),
+		giu.TreeNode(fmt.Sprintf("Environment variables (%d)", len(data.EnvVars))).Layout(
+			copyableVariables("envvar", data.EnvVars),
+		),
+		giu.TreeNode(fmt.Sprintf("Labels (%d)", len(data.Labels))).Layout(
+			copyableVariables("label", data.Labels),
+		),
+	)
+}
+
+// detailInfo shows a named value of the detail view, a click copies the value to clipboard
+func detailInfo(name string, value string) giu.Widget {
+	return giu.Layout{
+		giu.Selectable(fmt.Sprintf("%-8s %s", name, value)).OnClick(func() {
+			fmt.Printf("Copied %s %s to clipboard\n", name, value)
+			clipboard.Write(clipboard.FmtText, []byte(value))
+		}),
+	}
+}
+
+// processPanel shows the processes of the container, the process list is refreshed while the panel is shown
+func (a *App) processPanel(data ContainerData) giu.Widget {
+	if data.State != ContainerRunning && data.State != ContainerPaused {
+		return giu.Label("Container is not running")
+	}
+	a.processListShown = true
+	if a.processList == nil || a.processList.ID() != data.ID {
+		if a.processList != nil {
+			a.processList.Stop()
+		}
+		a.processList = NewProcessList(data.ID, a.listProcesses)
+	}
+	return a.processList.Render(func(pid int, signal string) {
+		a.signalProcess(data.ID, pid, signal)
+	})
+}
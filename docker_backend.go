@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	types_container "github.com/docker/docker/api/types/container"
+	types_event "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	"io"
+	"strings"
+)
+
+var _ ContainerBackend = &DockerBackend{}
+
+// DockerBackend talks to a Docker daemon using the Docker Engine API
+type DockerBackend struct {
+	cli *client.Client
+}
+
+// NewDockerBackend creates a backend for the Docker daemon configured by given client options
+func NewDockerBackend(opts ...client.Opt) (*DockerBackend, error) {
+	opts = append([]client.Opt{client.WithAPIVersionNegotiation()}, opts...)
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &DockerBackend{cli: cli}, nil
+}
+
+// NewDockerBackendFromEnv creates a backend for the Docker daemon configured by DOCKER_HOST etc.
+func NewDockerBackendFromEnv() (ContainerBackend, error) {
+	return NewDockerBackend(client.FromEnv)
+}
+
+func (b *DockerBackend) Name() string {
+	return "docker"
+}
+
+func (b *DockerBackend) Ping(ctx context.Context) error {
+	ping, err := b.cli.Ping(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ping.APIVersion) == 0 {
+		return fmt.Errorf("no API version reported by %s", b.cli.DaemonHost())
+	}
+	return nil
+}
+
+func (b *DockerBackend) ContainerList(ctx context.Context, all bool) ([]types_container.Summary, error) {
+	return b.cli.ContainerList(ctx, types_container.ListOptions{All: all})
+}
+
+func (b *DockerBackend) ContainerInspect(ctx context.Context, id string) (types_container.InspectResponse, error) {
+	return b.cli.ContainerInspect(ctx, id)
+}
+
+func (b *DockerBackend) ContainerInspectRaw(ctx context.Context, id string) ([]byte, error) {
+	_, raw, err := b.cli.ContainerInspectWithRaw(ctx, id, false)
+	return raw, err
+}
+
+func (b *DockerBackend) ContainerStats(ctx context.Context, id string) (StatsStream, error) {
+	response, err := b.cli.ContainerStats(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerStatsStream{
+		body:   response.Body,
+		osType: response.OSType,
+		dec:    json.NewDecoder(response.Body),
+	}, nil
+}
+
+func (b *DockerBackend) ContainerLogs(ctx context.Context, id string, options types_container.LogsOptions) (io.ReadCloser, error) {
+	return b.cli.ContainerLogs(ctx, id, options)
+}
+
+func (b *DockerBackend) Events(ctx context.Context) (<-chan types_event.Message, <-chan error) {
+	return b.cli.Events(ctx, types_event.ListOptions{})
+}
+
+func (b *DockerBackend) ContainerStart(ctx context.Context, id string) error {
+	return b.cli.ContainerStart(ctx, id, types_container.StartOptions{})
+}
+
+func (b *DockerBackend) ContainerStop(ctx context.Context, id string) error {
+	return b.cli.ContainerStop(ctx, id, types_container.StopOptions{})
+}
+
+func (b *DockerBackend) ContainerRestart(ctx context.Context, id string) error {
+	return b.cli.ContainerRestart(ctx, id, types_container.StopOptions{})
+}
+
+func (b *DockerBackend) ContainerPause(ctx context.Context, id string) error {
+	return b.cli.ContainerPause(ctx, id)
+}
+
+func (b *DockerBackend) ContainerUnpause(ctx context.Context, id string) error {
+	return b.cli.ContainerUnpause(ctx, id)
+}
+
+func (b *DockerBackend) ContainerKill(ctx context.Context, id string, signal string) error {
+	return b.cli.ContainerKill(ctx, id, signal)
+}
+
+func (b *DockerBackend) ContainerTop(ctx context.Context, id string, arguments []string) (types_container.TopResponse, error) {
+	return b.cli.ContainerTop(ctx, id, arguments)
+}
+
+// TopPIDScope returns the pids of the host running the daemon, ps is executed on the host
+func (b *DockerBackend) TopPIDScope() PIDScope {
+	if strings.HasPrefix(b.cli.DaemonHost(), "unix://") {
+		return LocalHostPIDs
+	}
+	return RemoteHostPIDs
+}
+
+func (b *DockerBackend) ContainerExecCreate(ctx context.Context, id string, options types_container.ExecOptions) (string, error) {
+	response, err := b.cli.ContainerExecCreate(ctx, id, options)
+	if err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+func (b *DockerBackend) ContainerExecAttach(ctx context.Context, execID string, options types_container.ExecAttachOptions) (types.HijackedResponse, error) {
+	return b.cli.ContainerExecAttach(ctx, execID, options)
+}
+
+func (b *DockerBackend) ContainerExecResize(ctx context.Context, execID string, height uint, width uint) error {
+	return b.cli.ContainerExecResize(ctx, execID, types_container.ResizeOptions{Height: height, Width: width})
+}
+
+func (b *DockerBackend) Close() error {
+	return b.cli.Close()
+}
+
+// dockerStatsStream decodes the stream of json encoded stats samples of the Docker Engine API
+type dockerStatsStream struct {
+	body   io.ReadCloser
+	osType string
+	dec    *json.Decoder
+}
+
+func (s *dockerStatsStream) OSType() string {
+	return s.osType
+}
+
+func (s *dockerStatsStream) Next() (*types_container.StatsResponse, error) {
+	var stats *types_container.StatsResponse
+	if err := s.dec.Decode(&stats); err != nil {
+		// continue decoding after the broken sample
+		s.dec = json.NewDecoder(io.MultiReader(s.dec.Buffered(), s.body))
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *dockerStatsStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const DefaultDockerContext = "default"
+
+// DockerContext is a context of the docker cli, see `docker context ls`
+type DockerContext struct {
+	Name        string
+	Host        string
+	TLSCertPath string
+}
+
+// Endpoint returns the endpoint to reach the daemon of the context
+func (c DockerContext) Endpoint() Endpoint {
+	return Endpoint{Name: c.Name, Host: c.Host, Backend: "docker", TLSCertPath: c.TLSCertPath}
+}
+
+// dockerConfigDir returns the directory of the docker cli configuration
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); len(dir) > 0 {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".docker"
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// currentDockerContext returns the name of the context selected by DOCKER_CONTEXT or by `docker context use`
+func currentDockerContext() string {
+	if name := os.Getenv("DOCKER_CONTEXT"); len(name) > 0 {
+		return name
+	}
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if content, err := os.ReadFile(filepath.Join(dockerConfigDir(), "config.json")); err == nil {
+		if err := json.Unmarshal(content, &config); err != nil {
+			fmt.Printf("Failed to parse docker config: %v\n", err)
+		}
+	}
+	if len(config.CurrentContext) == 0 {
+		return DefaultDockerContext
+	}
+	return config.CurrentContext
+}
+
+// listDockerContexts returns the default context and all contexts found in the docker cli configuration, sorted by name
+func listDockerContexts() ([]DockerContext, error) {
+	defaultHost := os.Getenv("DOCKER_HOST")
+	if len(defaultHost) == 0 {
+		defaultHost = "unix:///var/run/docker.sock"
+	}
+	contexts := []DockerContext{{Name: DefaultDockerContext, Host: defaultHost}}
+
+	metaDir := filepath.Join(dockerConfigDir(), "contexts", "meta")
+	metaFiles, err := filepath.Glob(filepath.Join(metaDir, "*", "meta.json"))
+	if err != nil {
+		return contexts, err
+	}
+	for _, metaFile := range metaFiles {
+		content, err := os.ReadFile(metaFile)
+		if err != nil {
+			return contexts, err
+		}
+		var meta struct {
+			Name      string
+			Endpoints map[string]struct {
+				Host string
+			}
+		}
+		if err := json.Unmarshal(content, &meta); err != nil {
+			return contexts, fmt.Errorf("failed to parse docker context %s: %v", metaFile, err)
+		}
+		docker, ok := meta.Endpoints["docker"]
+		if !ok || len(docker.Host) == 0 {
+			continue
+		}
+		context := DockerContext{Name: meta.Name, Host: docker.Host}
+		tlsDir := filepath.Join(dockerConfigDir(), "contexts", "tls", dockerContextDir(meta.Name), "docker")
+		if _, err := os.Stat(filepath.Join(tlsDir, "ca.pem")); err == nil {
+			context.TLSCertPath = tlsDir
+		}
+		contexts = append(contexts, context)
+	}
+	sort.SliceStable(contexts[1:], func(i int, j int) bool {
+		return contexts[1+i].Name < contexts[1+j].Name
+	})
+	return contexts, nil
+}
+
+// dockerContextDir returns the name of the directory used by the docker cli to store given context
+func dockerContextDir(name string) string {
+	hash := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(hash[:])
+}
+
+// findDockerContext returns the context of given name
+func findDockerContext(name string) (DockerContext, error) {
+	contexts, err := listDockerContexts()
+	if err != nil {
+		return DockerContext{}, err
+	}
+	for _, context := range contexts {
+		if context.Name == name {
+			return context, nil
+		}
+	}
+	return DockerContext{}, fmt.Errorf("unknown docker context %q", name)
+}
@@ -2,10 +2,8 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	types_container "github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
 	"io"
 	"math"
 	"strings"
@@ -14,18 +12,30 @@ import (
 )
 
 type ContainerInfo struct {
-	mutex sync.RWMutex
-	Data  ContainerData
+	mutex     sync.RWMutex
+	Data      ContainerData
+	following bool // stats of the container are followed
 
 	OnUpdated func()
 	OnStopped func()
 
+	Start   func()
 	Stop    func()
 	Restart func()
+	Pause   func()
+	Unpause func()
+	Kill    func(signal string)
+	Logs    func(ctx context.Context, tail string, line func(LogLine)) error
+	Exec    func(ctx context.Context, cmd []string, rows int, cols int) (*ExecSession, error)
+	Inspect func(ctx context.Context) ([]byte, error)
+
+	Processes     func(ctx context.Context) ([]Process, error)
+	SignalProcess func(pid int, signal string)
 }
 
 type ContainerData struct {
 	ID                           string
+	Host                         string
 	State                        ContainerState
 	Created                      int64
 	Name                         string
@@ -36,6 +46,10 @@ type ContainerData struct {
 	DockerComposeService         string
 	DockerComposeContainerNumber int
 	EnvVars                      map[string]string
+	Labels                       map[string]string
+	ExitCode                     int
+	OOMKilled                    bool
+	FinishedAt                   int64
 
 	LastUpdated                int64
 	CpuPercent                 float64
@@ -51,8 +65,11 @@ type ContainerData struct {
 	NetworkRx                  uint64
 	NetworkRxHistory           History
 	BlockRead                  uint64
+	BlockReadHistory           History
 	BlockWrite                 uint64
+	BlockWriteHistory          History
 	PIDs                       uint64
+	PIDsHistory                History
 	HealthUpdated              int64
 	HealthStatus               HealthState
 }
@@ -65,23 +82,88 @@ const (
 	ContainerRestarting   ContainerState = iota
 	ContainerStopping     ContainerState = iota
 	ContainerStopped      ContainerState = iota
+	ContainerExited       ContainerState = iota
+	ContainerCreated      ContainerState = iota
+	ContainerStarting     ContainerState = iota
+	ContainerPaused       ContainerState = iota
 )
 
+func (s ContainerState) String() string {
+	switch s {
+	case ContainerRunning:
+		return "running"
+	case ContainerRestarting:
+		return "restarting"
+	case ContainerStopping:
+		return "stopping"
+	case ContainerStopped:
+		return "stopped"
+	case ContainerExited:
+		return "exited"
+	case ContainerCreated:
+		return "created"
+	case ContainerStarting:
+		return "starting"
+	case ContainerPaused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+func (s ContainerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// IsTombstone returns true if the container is not running but still exists, e.g. after it exited
+func (s ContainerState) IsTombstone() bool {
+	return s == ContainerExited || s == ContainerCreated || s == ContainerStarting
+}
+
+// KillSignals are the signals offered to be sent to a container
+var KillSignals = []string{"SIGTERM", "SIGKILL", "SIGHUP", "SIGINT", "SIGQUIT", "SIGUSR1", "SIGUSR2"}
+
 type HealthState int
 
 const (
 	UnknownHealth HealthState = iota
 	Healthy       HealthState = iota
 	Unhealthy     HealthState = iota
+	// StartingHealth is the health of a container whose health check didn't pass yet since it (re-)started
+	StartingHealth HealthState = iota
 )
 
+func (s HealthState) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case Unhealthy:
+		return "unhealthy"
+	case StartingHealth:
+		return "starting"
+	default:
+		return "unknown"
+	}
+}
+
+func (s HealthState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 func NewContainerData(id string) ContainerData {
 	return ContainerData{
-		ID:                id,
-		State:             ContainerUnknownState,
-		CpuPercentHistory: NewHistory(),
-		MemoryHistory:     NewHistory(),
-		EnvVars:           make(map[string]string, 0),
+		ID:                         id,
+		State:                      ContainerUnknownState,
+		CpuPercentHistory:          NewHistory(),
+		CpuThrottledPercentHistory: NewHistory(),
+		MemoryHistory:              NewHistory(),
+		NetworkRxHistory:           NewHistory(),
+		NetworkTxHistory:           NewHistory(),
+		BlockReadHistory:           NewHistory(),
+		BlockWriteHistory:          NewHistory(),
+		PIDsHistory:                NewHistory(),
+		EnvVars:                    make(map[string]string, 0),
+		Labels:                     make(map[string]string, 0),
 	}
 }
 
@@ -110,29 +192,37 @@ func (c *ContainerInfo) Updated() {
 // functionality copied from
 // https://github.com/moby/moby/blob/eb131c5383db8cac633919f82abad86c99bffbe5/cli/command/container/stats_helpers.go
 
-func updateContainerStats(ctx context.Context, cli *client.Client, container *ContainerInfo) {
+func updateContainerStats(ctx context.Context, backend ContainerBackend, container *ContainerInfo) {
 	ctx_ := ctx
-	response, err := cli.ContainerStats(ctx, container.Data.ID, true)
+	// name of the container for log messages, its data is updated concurrently
+	container.mutex.RLock()
+	name := container.Data.AlternativeName
+	container.mutex.RUnlock()
+	stream, err := backend.ContainerStats(ctx, container.Data.ID)
 	if err != nil {
-		panic(err)
+		// the host may be unavailable, the collector reconnects to it and follows the container again
+		fmt.Printf("Failed to follow stats of container %s (%s): %v\n", name, container.Data.ID, err)
+		container.mutex.Lock()
+		container.following = false
+		container.mutex.Unlock()
+		return
 	}
 
 	var (
 		errors = make(chan error, 1)
 	)
 
-	dec := json.NewDecoder(response.Body)
-
 	go func() {
-		defer func(Body io.ReadCloser) {
-			err := Body.Close()
+		// cpu stats of previous sample, used if runtime doesn't provide PreCPUStats (e.g. Podman)
+		var prevCPUStats types_container.CPUStats
+		defer func(stream StatsStream) {
+			err := stream.Close()
 			if err != nil {
-				fmt.Printf("Failed to close body reader: %v", err)
+				fmt.Printf("Failed to close stats stream: %v", err)
 			}
-		}(response.Body)
+		}(stream)
 		for {
 			var (
-				stats               *types_container.StatsResponse
 				memPercent          = 0.0
 				cpuPercent          float64
 				cpuThrottledPercent = 0.0  // Only used on Linux
@@ -149,8 +239,8 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 				//
 			}
 
-			if err := dec.Decode(&stats); err != nil {
-				dec = json.NewDecoder(io.MultiReader(dec.Buffered(), response.Body))
+			stats, err := stream.Next()
+			if err != nil {
 				errors <- err
 				if err == io.EOF {
 					break
@@ -159,7 +249,7 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 				continue
 			}
 
-			daemonOSType := response.OSType
+			daemonOSType := stream.OSType()
 
 			if daemonOSType != "windows" {
 				// MemoryStats.Limit will never be 0 unless the container is not running and we haven't
@@ -167,8 +257,8 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 				if stats.MemoryStats.Limit != 0 {
 					memPercent = float64(stats.MemoryStats.Usage) / float64(stats.MemoryStats.Limit) * 100.0
 				}
-				cpuPercent = calculateCPUPercentUnix(stats)
-				cpuThrottledPercent = calculateCPUThrottledPercentUnix(stats)
+				cpuPercent = calculateCPUPercentUnix(stats, prevCPUStats)
+				cpuThrottledPercent = calculateCPUThrottledPercentUnix(stats, prevCPUStats)
 				blkRead, blkWrite = calculateBlockIO(stats.BlkioStats)
 				mem = float64(stats.MemoryStats.Usage)
 				memLimit = float64(stats.MemoryStats.Limit)
@@ -179,6 +269,7 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 				blkWrite = stats.StorageStats.WriteSizeBytes
 				mem = float64(stats.MemoryStats.PrivateWorkingSet)
 			}
+			prevCPUStats = stats.CPUStats
 
 			container.mutex.Lock()
 
@@ -196,32 +287,49 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 			container.Data.MemoryHistory.Add(Sample{float64(container.Data.LastUpdated), mem})
 			prevNetworkRx, prevNetworkTx := container.Data.NetworkRx, container.Data.NetworkTx
 			container.Data.NetworkRx, container.Data.NetworkTx = calculateNetwork(stats.Networks)
-			container.Data.NetworkRxHistory.Add(Sample{float64(container.Data.LastUpdated), float64(container.Data.NetworkRx - prevNetworkRx)})
-			container.Data.NetworkTxHistory.Add(Sample{float64(container.Data.LastUpdated), float64(container.Data.NetworkTx - prevNetworkTx)})
+			prevBlockRead, prevBlockWrite := container.Data.BlockRead, container.Data.BlockWrite
 			container.Data.BlockRead = blkRead
 			container.Data.BlockWrite = blkWrite
+			if !firstSeen {
+				// the first reading has no previous one, its counters are the total since the container started
+				container.Data.NetworkRxHistory.Add(Sample{float64(container.Data.LastUpdated), counterDelta(prevNetworkRx, container.Data.NetworkRx)})
+				container.Data.NetworkTxHistory.Add(Sample{float64(container.Data.LastUpdated), counterDelta(prevNetworkTx, container.Data.NetworkTx)})
+				container.Data.BlockReadHistory.Add(Sample{float64(container.Data.LastUpdated), counterDelta(prevBlockRead, blkRead)})
+				container.Data.BlockWriteHistory.Add(Sample{float64(container.Data.LastUpdated), counterDelta(prevBlockWrite, blkWrite)})
+			}
 			container.Data.PIDs = pidsStatsCurrent
+			container.Data.PIDsHistory.Add(Sample{float64(container.Data.LastUpdated), float64(pidsStatsCurrent)})
+
+			if historyStore != nil {
+				if err := historyStore.AppendLatest(&container.Data); err != nil {
+					fmt.Printf("Failed to store history of container %s: %v\n", container.Data.ID, err)
+				}
+			}
 
 			if firstSeen || healthStatusTooOld {
-				if inspect, err := cli.ContainerInspect(ctx, container.Data.ID); err == nil {
+				if inspect, err := backend.ContainerInspect(ctx, container.Data.ID); err == nil {
 					if firstSeen {
+						envVars := make(map[string]string, len(inspect.Config.Env))
 						for _, env := range inspect.Config.Env {
 							if s := strings.SplitN(env, "=", 2); len(s) == 2 {
-								container.Data.EnvVars[s[0]] = s[1]
+								envVars[s[0]] = s[1]
 							}
 						}
+						container.Data.EnvVars = envVars
 					}
 					container.Data.HealthUpdated = container.Data.LastUpdated
+					previousHealth := container.Data.HealthStatus
 					if inspect.State != nil && inspect.State.Health != nil {
-						switch inspect.State.Health.Status {
-						case "healthy":
-							container.Data.HealthStatus = Healthy
-						default:
-							container.Data.HealthStatus = Unhealthy
-						}
+						container.Data.HealthStatus = healthStateFromStatus(inspect.State.Health.Status)
 					} else {
 						container.Data.HealthStatus = UnknownHealth
 					}
+					if !firstSeen && healthChangeNotable(previousHealth, container.Data.HealthStatus) {
+						notification := containerNotification(NotifyHealth, container.Data,
+							fmt.Sprintf("Container %s became %s", container.Data.AlternativeName, container.Data.HealthStatus))
+						notification.HealthStatus = container.Data.HealthStatus.String()
+						notify(notification)
+					}
 				} else {
 					container.Data.HealthStatus = UnknownHealth
 					fmt.Printf("Failed to inspect container %s: %v", container.Data.ID, err)
@@ -231,7 +339,7 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 			stopped := false
 			if container.Data.State == ContainerRunning && container.Data.PIDs == 0 {
 				// double check that container is still running
-				if containers, err := cli.ContainerList(ctx_, types_container.ListOptions{}); err == nil {
+				if containers, err := backend.ContainerList(ctx_, false); err == nil {
 					found := false
 					for _, c := range containers {
 						if c.ID == container.Data.ID {
@@ -256,31 +364,82 @@ func updateContainerStats(ctx context.Context, cli *client.Client, container *Co
 
 			errors <- nil // we just handled a valid update
 		}
-		fmt.Printf("Done following stats of container %s (%s)\n", container.Data.AlternativeName, container.Data.ID)
+		fmt.Printf("Done following stats of container %s (%s)\n", name, container.Data.ID)
 	}()
 	for {
 		select {
 		case <-time.After(2 * time.Second):
-			fmt.Printf("Timeout while following stats of container %s (%s)\n", container.Data.AlternativeName, container.Data.ID)
+			fmt.Printf("Timeout while following stats of container %s (%s)\n", name, container.Data.ID)
 		case <-ctx.Done():
-			fmt.Printf("Done following stats of container %s (%s)\n", container.Data.AlternativeName, container.Data.ID)
+			fmt.Printf("Done following stats of container %s (%s)\n", name, container.Data.ID)
 			return
 		case err := <-errors:
 			if err != nil {
-				fmt.Printf("Error while following stats of container %s (%s): %v\n", container.Data.AlternativeName, container.Data.ID, err)
+				fmt.Printf("Error while following stats of container %s (%s): %v\n", name, container.Data.ID, err)
 				continue
 			}
 		}
 	}
 }
 
-func calculateCPUPercentUnix(stats *types_container.StatsResponse) float64 {
+// containerStateFromStatus maps the state reported by the runtime, e.g. "running" or "exited"
+func containerStateFromStatus(status string) ContainerState {
+	switch strings.ToLower(status) {
+	case "running":
+		return ContainerRunning
+	case "restarting":
+		return ContainerRestarting
+	case "paused":
+		return ContainerPaused
+	case "removing":
+		return ContainerStopping
+	case "exited", "dead", "stopped":
+		return ContainerExited
+	case "created", "configured", "initialized":
+		return ContainerCreated
+	default:
+		return ContainerUnknownState
+	}
+}
+
+// healthStateFromStatus maps the health status reported by the runtime,
+// Podman may report it capitalized or empty while no check has been run yet.
+func healthStateFromStatus(status string) HealthState {
+	switch strings.ToLower(status) {
+	case "healthy":
+		return Healthy
+	case "starting":
+		return StartingHealth
+	case "", "none":
+		return UnknownHealth
+	default:
+		return Unhealthy
+	}
+}
+
+// healthChangeNotable returns true if the change of the health is notified,
+// a (re-)started container that becomes healthy after starting is not worth a notification
+func healthChangeNotable(previous HealthState, current HealthState) bool {
+	return current != previous && current != StartingHealth && !(previous == StartingHealth && current == Healthy)
+}
+
+// preCPUStats returns the cpu stats of the previous reading,
+// falls back to given cpu stats of previous sample if the runtime didn't provide them (e.g. Podman).
+func preCPUStats(stats *types_container.StatsResponse, prevCPUStats types_container.CPUStats) types_container.CPUStats {
+	if stats.PreCPUStats.SystemUsage == 0 && stats.PreCPUStats.CPUUsage.TotalUsage == 0 {
+		return prevCPUStats
+	}
+	return stats.PreCPUStats
+}
+
+func calculateCPUPercentUnix(stats *types_container.StatsResponse, prevCPUStats types_container.CPUStats) float64 {
 	var (
 		cpuPercent = 0.0
+		pre        = preCPUStats(stats, prevCPUStats)
 		// calculate the change for the cpu usage of the container in between readings
-		cpuDelta = float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+		cpuDelta = float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(pre.CPUUsage.TotalUsage)
 		// calculate the change for the entire system between readings
-		systemDelta = float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+		systemDelta = float64(stats.CPUStats.SystemUsage) - float64(pre.SystemUsage)
 	)
 
 	if systemDelta > 0.0 && cpuDelta > 0.0 {
@@ -290,13 +449,14 @@ func calculateCPUPercentUnix(stats *types_container.StatsResponse) float64 {
 	return cpuPercent
 }
 
-func calculateCPUThrottledPercentUnix(stats *types_container.StatsResponse) float64 {
+func calculateCPUThrottledPercentUnix(stats *types_container.StatsResponse, prevCPUStats types_container.CPUStats) float64 {
 	var (
 		cpuThrottledPercent = 0.0
+		pre                 = preCPUStats(stats, prevCPUStats)
 		// calculate the change for the total periods of the container in between readings
-		periodsDelta = float64(stats.CPUStats.ThrottlingData.Periods) - float64(stats.PreCPUStats.ThrottlingData.Periods)
+		periodsDelta = float64(stats.CPUStats.ThrottlingData.Periods) - float64(pre.ThrottlingData.Periods)
 		// calculate the change of throttled periods of the container between readings
-		throttledPeriodsDelta = float64(stats.CPUStats.ThrottlingData.ThrottledPeriods) - float64(stats.PreCPUStats.ThrottlingData.ThrottledPeriods)
+		throttledPeriodsDelta = float64(stats.CPUStats.ThrottlingData.ThrottledPeriods) - float64(pre.ThrottlingData.ThrottledPeriods)
 	)
 
 	if periodsDelta > 0.0 && throttledPeriodsDelta > 0.0 {
@@ -342,3 +502,11 @@ func calculateNetwork(network map[string]types_container.NetworkStats) (uint64,
 	}
 	return rx, tx
 }
+
+// counterDelta returns the increase of a counter, a counter reset (e.g. by restarting the container) counts from zero
+func counterDelta(prev uint64, current uint64) float64 {
+	if current < prev {
+		return float64(current)
+	}
+	return float64(current - prev)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/client"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Endpoint describes how to reach a container runtime
+type Endpoint struct {
+	// Name is shown in the UI to tell containers of different hosts apart
+	Name string
+	// Host is the address of the runtime, e.g. unix:///var/run/docker.sock, tcp://build-vm:2376 or ssh://user@staging
+	Host string
+	// Backend is the kind of runtime, "docker" or "podman"
+	Backend string
+	// TLSCertPath is a directory containing ca.pem, cert.pem and key.pem to talk TLS to a tcp host
+	TLSCertPath string
+
+	named bool // name was given explicitly instead of being derived from the host
+}
+
+// ParseEndpoint parses an endpoint given as "[name=]host[?backend=podman&tlscertpath=dir]"
+func ParseEndpoint(spec string) (Endpoint, error) {
+	endpoint := Endpoint{Backend: "docker"}
+	if name, host, ok := strings.Cut(spec, "="); ok && !strings.Contains(name, "://") {
+		endpoint.Name = name
+		endpoint.named = true
+		spec = host
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return endpoint, fmt.Errorf("invalid endpoint %q: %v", spec, err)
+	}
+	query := u.Query()
+	if backend := query.Get("backend"); len(backend) > 0 {
+		if backend != "docker" && backend != "podman" {
+			return endpoint, fmt.Errorf("invalid endpoint %q: unknown backend %q", spec, backend)
+		}
+		endpoint.Backend = backend
+	}
+	endpoint.TLSCertPath = query.Get("tlscertpath")
+	u.RawQuery = ""
+	switch u.Scheme {
+	case "unix":
+		if len(endpoint.Name) == 0 {
+			endpoint.Name = "local"
+		}
+	case "tcp", "ssh":
+		if len(endpoint.Name) == 0 {
+			endpoint.Name = u.Hostname()
+		}
+	default:
+		return endpoint, fmt.Errorf("invalid endpoint %q: unsupported scheme %q, expected unix, tcp or ssh", spec, u.Scheme)
+	}
+	if len(endpoint.TLSCertPath) > 0 && u.Scheme != "tcp" {
+		return endpoint, fmt.Errorf("invalid endpoint %q: tlscertpath is only supported for tcp", spec)
+	}
+	endpoint.Host = u.String()
+	return endpoint, nil
+}
+
+// uniqueEndpointName makes the name of the endpoint unique among the names of given endpoints.
+// A name derived from the host gets a numeric suffix, e.g. "local-2" for the second unnamed unix endpoint,
+// a duplicate name given explicitly is an error.
+func uniqueEndpointName(endpoint *Endpoint, endpoints []Endpoint) error {
+	taken := func(name string) bool {
+		return slices.ContainsFunc(endpoints, func(other Endpoint) bool {
+			return other.Name == name
+		})
+	}
+	if !taken(endpoint.Name) {
+		return nil
+	}
+	if endpoint.named {
+		return fmt.Errorf("duplicate endpoint name %q", endpoint.Name)
+	}
+	for i := 2; ; i++ {
+		if name := fmt.Sprintf("%s-%d", endpoint.Name, i); !taken(name) {
+			endpoint.Name = name
+			return nil
+		}
+	}
+}
+
+func (e Endpoint) String() string {
+	return fmt.Sprintf("%s (%s)", e.Name, e.Host)
+}
+
+// BackendFactory returns the factory creating the backend for this endpoint
+func (e Endpoint) BackendFactory() BackendFactory {
+	return func() (ContainerBackend, error) {
+		var opts []client.Opt
+		if strings.HasPrefix(e.Host, "ssh://") {
+			// the http host is a dummy, all connections are made by the ssh dialer
+			opts = append(opts, client.WithHost("http://docker.example.com"), client.WithDialContext(sshDialer(e.Host)))
+		} else {
+			opts = append(opts, client.WithHost(e.Host))
+		}
+		if len(e.TLSCertPath) > 0 {
+			opts = append(opts, client.WithTLSClientConfig(
+				filepath.Join(e.TLSCertPath, "ca.pem"),
+				filepath.Join(e.TLSCertPath, "cert.pem"),
+				filepath.Join(e.TLSCertPath, "key.pem"),
+			))
+		}
+		docker, err := NewDockerBackend(opts...)
+		if err != nil {
+			return nil, err
+		}
+		if e.Backend == "podman" {
+			return &PodmanBackend{DockerBackend: docker}, nil
+		}
+		return docker, nil
+	}
+}
+
+// sshDialer returns a dialer connecting to the docker daemon of the remote host of given ssh url,
+// like the docker cli it uses "docker system dial-stdio" on the remote host.
+func sshDialer(sshUrl string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		u, err := url.Parse(sshUrl)
+		if err != nil {
+			return nil, err
+		}
+		var args []string
+		if u.User != nil {
+			args = append(args, "-l", u.User.Username())
+		}
+		if len(u.Port()) > 0 {
+			args = append(args, "-p", u.Port())
+		}
+		args = append(args, "--", u.Hostname(), "docker", "system", "dial-stdio")
+		cmd := exec.Command("ssh", args...)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to run ssh to %s: %v", u.Host, err)
+		}
+		return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout, host: u.Host}, nil
+	}
+}
+
+var _ net.Conn = &commandConn{}
+
+// commandConn is a net.Conn talking to stdin / stdout of a command
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	host   string
+}
+
+func (c *commandConn) Read(b []byte) (int, error) {
+	return c.stdout.Read(b)
+}
+
+func (c *commandConn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+func (c *commandConn) Close() error {
+	_ = c.stdin.Close()
+	_ = c.stdout.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr {
+	return commandAddr("local")
+}
+
+func (c *commandConn) RemoteAddr() net.Addr {
+	return commandAddr(c.host)
+}
+
+// deadlines are not supported by pipes of a command
+
+func (c *commandConn) SetDeadline(_ time.Time) error {
+	return nil
+}
+
+func (c *commandConn) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+func (c *commandConn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
+
+type commandAddr string
+
+func (a commandAddr) Network() string {
+	return "command"
+}
+
+func (a commandAddr) String() string {
+	return string(a)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Endpoint
+	}{
+		{"unix:///var/run/docker.sock", Endpoint{Name: "local", Host: "unix:///var/run/docker.sock", Backend: "docker"}},
+		{"tcp://build-vm:2376?tlscertpath=/certs", Endpoint{Name: "build-vm", Host: "tcp://build-vm:2376", Backend: "docker", TLSCertPath: "/certs"}},
+		{"staging=ssh://user@staging", Endpoint{Name: "staging", Host: "ssh://user@staging", Backend: "docker", named: true}},
+		{"unix:///run/podman/podman.sock?backend=podman", Endpoint{Name: "local", Host: "unix:///run/podman/podman.sock", Backend: "podman"}},
+	}
+	for _, test := range tests {
+		endpoint, err := ParseEndpoint(test.spec)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.spec, err)
+		} else if endpoint != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.spec, test.expected, endpoint)
+		}
+	}
+
+	for _, spec := range []string{"http://host", "unix:///sock?backend=containerd", "ssh://host?tlscertpath=/certs"} {
+		if _, err := ParseEndpoint(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestUniqueEndpointName(t *testing.T) {
+	var endpoints endpointsFlag
+	for _, spec := range []string{"unix:///var/run/docker.sock", "unix:///run/podman/podman.sock?backend=podman", "tcp://vm:2375", "tcp://vm:2376", "unix:///tmp/docker.sock"} {
+		if err := endpoints.Set(spec); err != nil {
+			t.Fatalf("%s: unexpected error: %v", spec, err)
+		}
+	}
+	expected := []string{"local", "local-2", "vm", "vm-2", "local-3"}
+	for idx, endpoint := range endpoints {
+		if endpoint.Name != expected[idx] {
+			t.Errorf("expected endpoint %s to be named %q, got %q", endpoint.Host, expected[idx], endpoint.Name)
+		}
+	}
+
+	if err := endpoints.Set("vm=tcp://other:2375"); err == nil {
+		t.Errorf("expected an error for a duplicate explicit name")
+	}
+}
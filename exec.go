@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"github.com/docker/docker/api/types"
+	types_container "github.com/docker/docker/api/types/container"
+)
+
+// ExecSession is an interactive process with a TTY executed in a container
+type ExecSession struct {
+	backend ContainerBackend
+	execID  string
+	conn    types.HijackedResponse
+}
+
+// startExecSession executes given command with a TTY of given size in the container
+func startExecSession(ctx context.Context, backend ContainerBackend, id string, cmd []string, rows int, cols int) (*ExecSession, error) {
+	consoleSize := &[2]uint{uint(rows), uint(cols)}
+	execID, err := backend.ContainerExecCreate(ctx, id, types_container.ExecOptions{
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=vt100"},
+		Cmd:          cmd,
+		ConsoleSize:  consoleSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	conn, err := backend.ContainerExecAttach(ctx, execID, types_container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: consoleSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ExecSession{backend: backend, execID: execID, conn: conn}, nil
+}
+
+// Read reads the output of the TTY, returns io.EOF when the process exited
+func (s *ExecSession) Read(p []byte) (int, error) {
+	return s.conn.Reader.Read(p)
+}
+
+// Write sends input to the TTY
+func (s *ExecSession) Write(p []byte) (int, error) {
+	return s.conn.Conn.Write(p)
+}
+
+// Resize changes the size of the TTY
+func (s *ExecSession) Resize(ctx context.Context, rows int, cols int) error {
+	return s.backend.ContainerExecResize(ctx, s.execID, uint(rows), uint(cols))
+}
+
+// Close closes the connection to the TTY, the shell usually terminates on hangup
+func (s *ExecSession) Close() {
+	s.conn.Close()
+}
@@ -18,7 +18,7 @@ func (s *GridState) Dispose() {
 
 // GridBuilder Arrange widgets from given array of Samples in a grid layout where all grid items share equal size
 // The selected item will be highlighted by a border.
-func GridBuilder[T any](id string, columns int, rows int, values []T, selected int, onClicked func(i int), builder func(i int, selected bool, item T) giu.Widget) giu.Layout {
+func GridBuilder[T any](id string, columns int, rows int, values []T, selected int, onClicked func(i int), onDoubleClicked func(i int), builder func(i int, selected bool, item T) giu.Widget) giu.Layout {
 	var layout giu.Layout
 
 	layout = append(layout, giu.Custom(func() {
@@ -81,6 +81,10 @@ func GridBuilder[T any](id string, columns int, rows int, values []T, selected i
 						if onClicked != nil {
 							onClicked(itemIdx)
 						}
+					}).OnDClick(giu.MouseButtonLeft, func() {
+						if onDoubleClicked != nil {
+							onDoubleClicked(itemIdx)
+						}
 					}).Build()
 				}
 			}))
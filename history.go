@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"math"
+	"slices"
 	"time"
 )
 
@@ -10,29 +12,121 @@ type Sample struct {
 	value     float64
 }
 
+// Bucket aggregates all samples of a time interval of a history tier
+type Bucket struct {
+	timestamp float64 // start of interval
+	min       float64
+	max       float64
+	sum       float64
+	count     int
+}
+
+func (b *Bucket) avg() float64 {
+	return b.sum / float64(b.count)
+}
+
+// HistoryTier keeps buckets of samples aggregated at given resolution
+type HistoryTier struct {
+	Resolution float64 // seconds per bucket
+	Buckets    []Bucket
+}
+
 type History struct {
 	Samples []Sample
+	Tiers   []HistoryTier
 }
 
-const MaxHistorySamples = 512
+// MaxHistorySamples is the default max number of raw samples and the max number of buckets of every tier, see Config
+var MaxHistorySamples = 512
+
+// HistoryTierResolutions are the resolutions of the tiers of downsampled samples, finest first
+var HistoryTierResolutions = []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute}
 
 func NewHistory() History {
-	return History{Samples: make([]Sample, 0)}
+	return History{Samples: make([]Sample, 0), Tiers: newHistoryTiers()}
+}
+
+func newHistoryTiers() []HistoryTier {
+	tiers := make([]HistoryTier, len(HistoryTierResolutions))
+	for idx, resolution := range HistoryTierResolutions {
+		tiers[idx] = HistoryTier{Resolution: resolution.Seconds(), Buckets: make([]Bucket, 0)}
+	}
+	return tiers
+}
+
+// Clone returns a deep copy of the history, sharing neither samples nor buckets with the original
+func (h *History) Clone() History {
+	clone := History{Samples: slices.Clone(h.Samples), Tiers: slices.Clone(h.Tiers)}
+	for idx := range clone.Tiers {
+		clone.Tiers[idx].Buckets = slices.Clone(clone.Tiers[idx].Buckets)
+	}
+	return clone
 }
 
 // Add a new sample at the end of history and maintain max history size
 func (h *History) Add(sample Sample) {
 	if !time.Unix(int64(sample.timestamp), 0).IsZero() {
 		h.Samples = append(h.Samples, sample)
-		if len(h.Samples) > MaxHistorySamples {
-			copy(h.Samples[0:], h.Samples[len(h.Samples)-MaxHistorySamples:])
-			h.Samples = h.Samples[:MaxHistorySamples]
+		if maxSamples := tunables().MaxHistorySamples; len(h.Samples) > maxSamples {
+			copy(h.Samples[0:], h.Samples[len(h.Samples)-maxSamples:])
+			h.Samples = h.Samples[:maxSamples]
+		}
+		if h.Tiers == nil {
+			h.Tiers = newHistoryTiers()
+		}
+		for idx := range h.Tiers {
+			h.Tiers[idx].add(sample)
 		}
 	}
 }
 
-// GetXY return history samples as two arrays for x and y data
-func (h *History) GetXY() ([]float64, []float64) {
+// add the sample to the bucket of its interval and maintain max tier size
+func (t *HistoryTier) add(sample Sample) {
+	start := math.Floor(sample.timestamp/t.Resolution) * t.Resolution
+	if last := len(t.Buckets) - 1; last >= 0 && t.Buckets[last].timestamp == start {
+		bucket := &t.Buckets[last]
+		bucket.min = math.Min(bucket.min, sample.value)
+		bucket.max = math.Max(bucket.max, sample.value)
+		bucket.sum += sample.value
+		bucket.count++
+		return
+	}
+	t.Buckets = append(t.Buckets, Bucket{timestamp: start, min: sample.value, max: sample.value, sum: sample.value, count: 1})
+	if maxBuckets := tunables().MaxHistorySamples; len(t.Buckets) > maxBuckets {
+		copy(t.Buckets[0:], t.Buckets[len(t.Buckets)-maxBuckets:])
+		t.Buckets = t.Buckets[:maxBuckets]
+	}
+}
+
+// tier returns the finest tier covering the time window starting at given timestamp,
+// nil if raw samples cover it.
+func (h *History) tier(from float64) *HistoryTier {
+	if len(h.Samples) == 0 || h.Samples[0].timestamp <= from || len(h.Samples) < tunables().MaxHistorySamples {
+		return nil
+	}
+	for idx := range h.Tiers {
+		if len(h.Tiers[idx].Buckets) > 0 && h.Tiers[idx].Buckets[0].timestamp <= from {
+			return &h.Tiers[idx]
+		}
+	}
+	if len(h.Tiers) > 0 {
+		return &h.Tiers[len(h.Tiers)-1]
+	}
+	return nil
+}
+
+// GetXY return history samples as two arrays for x and y data,
+// samples are taken from the finest tier covering given time window, the average of a bucket is used as value.
+func (h *History) GetXY(from, until float64) ([]float64, []float64) {
+	if tier := h.tier(from); tier != nil {
+		x := make([]float64, len(tier.Buckets))
+		y := make([]float64, len(tier.Buckets))
+		for idx := range tier.Buckets {
+			x[idx] = tier.Buckets[idx].timestamp
+			y[idx] = tier.Buckets[idx].avg()
+		}
+		return x, y
+	}
 	x := make([]float64, len(h.Samples))
 	y := make([]float64, len(h.Samples))
 	for idx, sample := range h.Samples {
@@ -46,6 +140,15 @@ func (h *History) GetXY() ([]float64, []float64) {
 func (h *History) GetYMinMax(from, until float64) (float64, float64) {
 	min := math.MaxFloat64
 	max := -math.MaxFloat64
+	if tier := h.tier(from); tier != nil {
+		for _, bucket := range tier.Buckets {
+			if bucket.timestamp >= from && bucket.timestamp <= until {
+				max = math.Max(max, bucket.max)
+				min = math.Min(min, bucket.min)
+			}
+		}
+		return min, max
+	}
 	for _, sample := range h.Samples {
 		if sample.timestamp >= from && sample.timestamp <= until {
 			max = math.Max(max, sample.value)
@@ -58,6 +161,16 @@ func (h *History) GetYMinMax(from, until float64) (float64, float64) {
 func (h *History) GetYAvg(from, until float64) float64 {
 	avg := 0.
 	samples := 0
+	if tier := h.tier(from); tier != nil {
+		for _, bucket := range tier.Buckets {
+			if bucket.timestamp >= from && bucket.timestamp <= until {
+				avg += bucket.sum
+				samples += bucket.count
+			}
+		}
+		avg /= float64(samples)
+		return avg
+	}
 	for _, sample := range h.Samples {
 		if sample.timestamp >= from && sample.timestamp <= until {
 			avg += sample.value
@@ -67,3 +180,12 @@ func (h *History) GetYAvg(from, until float64) float64 {
 	avg /= float64(samples)
 	return avg
 }
+
+// MarshalJSON encodes history as array of [timestamp, value] pairs
+func (h History) MarshalJSON() ([]byte, error) {
+	samples := make([][2]float64, len(h.Samples))
+	for idx, sample := range h.Samples {
+		samples[idx] = [2]float64{sample.timestamp, sample.value}
+	}
+	return json.Marshal(samples)
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/AllenDang/giu"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formats of exported history
+const (
+	HistoryExportCSV   = "csv"
+	HistoryExportJSONL = "jsonl"
+)
+
+// historyMetricNames are the names of the history series in exported files, like in the JSON API
+var historyMetricNames = map[HistoryMetric]string{
+	CpuPercentHistoryMetric:          "cpuPercent",
+	CpuThrottledPercentHistoryMetric: "cpuThrottledPercent",
+	MemoryHistoryMetric:              "memory",
+	NetworkRxHistoryMetric:           "networkRx",
+	NetworkTxHistoryMetric:           "networkTx",
+	BlockReadHistoryMetric:           "blockRead",
+	BlockWriteHistoryMetric:          "blockWrite",
+	PIDsHistoryMetric:                "pids",
+}
+
+// historyExportRecord is a raw sample or an aggregated bucket of a history series
+type historyExportRecord struct {
+	Time           string  `json:"time"`
+	Host           string  `json:"host"`
+	ContainerID    string  `json:"containerId"`
+	Container      string  `json:"container"`
+	ComposeProject string  `json:"composeProject,omitempty"`
+	Metric         string  `json:"metric"`
+	Resolution     string  `json:"resolution"` // "raw" for samples, the interval of aggregated buckets otherwise
+	Value          float64 `json:"value"`
+	Min            float64 `json:"min"`
+	Max            float64 `json:"max"`
+}
+
+var historyExportColumns = []string{"time", "host", "container_id", "container", "compose_project", "metric", "resolution", "value", "min", "max"}
+
+func (r historyExportRecord) csv() []string {
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return []string{r.Time, r.Host, r.ContainerID, r.Container, r.ComposeProject, r.Metric, r.Resolution,
+		format(r.Value), format(r.Min), format(r.Max)}
+}
+
+func historyExportTime(timestamp float64) string {
+	seconds, fraction := math.Modf(timestamp)
+	return time.Unix(int64(seconds), int64(fraction*1e9)).UTC().Format(time.RFC3339Nano)
+}
+
+// historyExportRecords returns the records of the history in chronological order.
+// Raw samples are complemented by the buckets of the tiers covering the time before the oldest raw sample.
+func historyExportRecords(history *History) []historyExportRecord {
+	records := make([]historyExportRecord, 0, len(history.Samples))
+	for _, sample := range history.Samples {
+		records = append(records, historyExportRecord{
+			Time:       historyExportTime(sample.timestamp),
+			Resolution: "raw",
+			Value:      sample.value,
+			Min:        sample.value,
+			Max:        sample.value,
+		})
+	}
+
+	until := math.Inf(1)
+	if len(history.Samples) > 0 {
+		until = history.Samples[0].timestamp
+	}
+	for _, tier := range history.Tiers {
+		older := make([]historyExportRecord, 0)
+		for _, bucket := range tier.Buckets {
+			if bucket.timestamp+tier.Resolution > until {
+				break
+			}
+			older = append(older, historyExportRecord{
+				Time:       historyExportTime(bucket.timestamp),
+				Resolution: formatTimeRange(time.Duration(tier.Resolution * float64(time.Second))),
+				Value:      bucket.avg(),
+				Min:        bucket.min,
+				Max:        bucket.max,
+			})
+		}
+		records = append(older, records...)
+		if len(tier.Buckets) > 0 {
+			until = math.Min(until, tier.Buckets[0].timestamp)
+		}
+	}
+	return records
+}
+
+// writeHistoryExport writes the history series of all containers in given format
+func writeHistoryExport(w io.Writer, format string, containers []ContainerData) error {
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	switch format {
+	case HistoryExportCSV:
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(historyExportColumns); err != nil {
+			return err
+		}
+	case HistoryExportJSONL:
+		jsonEncoder = json.NewEncoder(w)
+	default:
+		return fmt.Errorf("unknown export format %q, expected %q or %q", format, HistoryExportCSV, HistoryExportJSONL)
+	}
+
+	metrics := make([]HistoryMetric, 0, len(historyMetricNames))
+	for metric := range historyMetricNames {
+		metrics = append(metrics, metric)
+	}
+	slices.Sort(metrics)
+
+	for idx := range containers {
+		data := &containers[idx]
+		series := data.HistorySeries()
+		for _, metric := range metrics {
+			for _, record := range historyExportRecords(series[metric]) {
+				record.Host = data.Host
+				record.ContainerID = data.ID
+				record.Container = data.AlternativeName
+				record.ComposeProject = data.DockerComposeProject
+				record.Metric = historyMetricNames[metric]
+				var err error
+				if csvWriter != nil {
+					err = csvWriter.Write(record.csv())
+				} else {
+					err = jsonEncoder.Encode(record)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return nil
+}
+
+// ExportHistory writes the history series of the containers to a file in given format
+func ExportHistory(path string, format string, containers []ContainerData) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := writeHistoryExport(w, format, containers); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// historyExportFormat returns the export format matching the extension of the path, defaults to CSV
+func historyExportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return HistoryExportJSONL
+	default:
+		return HistoryExportCSV
+	}
+}
+
+// minHistoryExportIDPrefix is the min length of a container id prefix matching a target of the export
+const minHistoryExportIDPrefix = 4
+
+// historyExportTargets returns the containers matching the target, preferring exact matches:
+// the name of a container, the name of a compose service, a compose project or a prefix of a container id.
+// It fails if the target matches several containers or compose projects of several hosts.
+func historyExportTargets(containers []ContainerData, target string) ([]ContainerData, error) {
+	matches := []struct {
+		match   func(data ContainerData) bool
+		project bool // all matching containers of a host are exported
+	}{
+		{func(data ContainerData) bool { return data.Name == target }, false},
+		{func(data ContainerData) bool { return data.AlternativeName == target }, false},
+		{func(data ContainerData) bool { return data.DockerComposeProject == target }, true},
+		{func(data ContainerData) bool {
+			return len(target) >= minHistoryExportIDPrefix && strings.HasPrefix(data.ID, target)
+		}, false},
+	}
+	for _, m := range matches {
+		matching := make([]ContainerData, 0)
+		hosts := make([]string, 0)
+		for _, data := range containers {
+			if m.match(data) {
+				matching = append(matching, data)
+				if !slices.Contains(hosts, data.Host) {
+					hosts = append(hosts, data.Host)
+				}
+			}
+		}
+		if len(matching) > 1 && (!m.project || len(hosts) > 1) {
+			names := make([]string, 0, len(matching))
+			for _, data := range matching {
+				names = append(names, fmt.Sprintf("%s (%s on %s)", data.Name, data.ID, data.Host))
+			}
+			return nil, fmt.Errorf("%q is ambiguous, it matches %s", target, strings.Join(names, ", "))
+		}
+		if len(matching) > 0 {
+			return matching, nil
+		}
+	}
+	return nil, nil
+}
+
+// exportHistoryFromCli waits for the containers of the target to be collected and exports their history to path
+func exportHistoryFromCli(target string, path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		data, _ := collectContainerData()
+		containers, err := historyExportTargets(data, target)
+		if err != nil {
+			return err
+		}
+		if len(containers) > 0 {
+			// give the collectors time to list all containers of a compose project
+			time.Sleep(tunables().RefreshInterval)
+			data, _ = collectContainerData()
+			if containers, err = historyExportTargets(data, target); err != nil {
+				return err
+			}
+			fmt.Printf("Exporting history of %d containers to %s\n", len(containers), path)
+			return ExportHistory(path, historyExportFormat(path), containers)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("no container or compose project %q found", target)
+		}
+		time.Sleep(tunables().RefreshInterval)
+	}
+}
+
+var historyExportFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// defaultHistoryExportDir returns the home dir, the current dir if unknown
+func defaultHistoryExportDir() string {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return dir
+}
+
+// defaultHistoryExportFile returns a file name after the exported container or project
+func defaultHistoryExportFile(name string, format string) string {
+	name = historyExportFileChars.ReplaceAllString(name, "_")
+	return fmt.Sprintf("%s-history-%s.%s", name, time.Now().Format("20060102-150405"), format)
+}
+
+// historyExportSubDirs returns the sorted names of the sub directories of dir, skipping hidden ones
+func historyExportSubDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	return dirs, nil
+}
+
+// showHistoryExport opens the dialog to save the history of given container or its compose project
+func (a *App) showHistoryExport(containerId string) {
+	idx := a.getContainerByIdx(containerId)
+	if idx < 0 {
+		return
+	}
+	a.historyExportId = containerId
+	a.historyExportProject = false
+	a.historyExportFormat = HistoryExportCSV
+	a.historyExportDefaultFile = defaultHistoryExportFile(a.containerData[idx].AlternativeName, a.historyExportFormat)
+	a.historyExportFile = a.historyExportDefaultFile
+	a.setHistoryExportState(false, false, "")
+	if len(a.historyExportDir) == 0 {
+		a.historyExportDir = defaultHistoryExportDir()
+	}
+	a.browseHistoryExportDir(a.historyExportDir)
+	a.historyExportPopup.Open()
+}
+
+// browseHistoryExportDir lists the sub directories of dir to choose the directory of the export
+func (a *App) browseHistoryExportDir(dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	dirs, err := historyExportSubDirs(dir)
+	if err != nil {
+		a.setHistoryExportState(false, false, fmt.Sprintf("Failed to list directory %s: %v", dir, err))
+		return
+	}
+	a.historyExportDir = dir
+	a.historyExportSubDirs = dirs
+}
+
+// historyExportState returns whether an export is running, whether it succeeded and the error of the last export
+func (a *App) historyExportState() (bool, bool, string) {
+	a.historyExportMutex.Lock()
+	defer a.historyExportMutex.Unlock()
+	return a.historyExporting, a.historyExportSaved, a.historyExportError
+}
+
+func (a *App) setHistoryExportState(exporting bool, saved bool, err string) {
+	a.historyExportMutex.Lock()
+	defer a.historyExportMutex.Unlock()
+	a.historyExporting = exporting
+	a.historyExportSaved = saved
+	a.historyExportError = err
+}
+
+// historyExportContainers returns the containers whose history gets exported and the name of the export
+func (a *App) historyExportContainers() ([]ContainerData, string) {
+	idx := a.getContainerByIdx(a.historyExportId)
+	if idx < 0 {
+		return nil, ""
+	}
+	selected := a.containerData[idx]
+	if !a.historyExportProject || len(selected.DockerComposeProject) == 0 {
+		return []ContainerData{selected}, selected.AlternativeName
+	}
+	containers := make([]ContainerData, 0)
+	for _, data := range a.containerData {
+		if data.DockerComposeProject == selected.DockerComposeProject && data.Host == selected.Host {
+			containers = append(containers, data)
+		}
+	}
+	return containers, selected.DockerComposeProject
+}
+
+// setHistoryExportOptions changes scope and format of the export, the file name follows unless it got edited
+func (a *App) setHistoryExportOptions(project bool, format string) {
+	a.historyExportProject = project
+	a.historyExportFormat = format
+	if a.historyExportFile == a.historyExportDefaultFile {
+		_, name := a.historyExportContainers()
+		a.historyExportDefaultFile = defaultHistoryExportFile(name, format)
+		a.historyExportFile = a.historyExportDefaultFile
+	}
+}
+
+// startHistoryExport writes the export file in the background, see historyExportState
+func (a *App) startHistoryExport(containers []ContainerData, name string) {
+	if len(containers) == 0 {
+		a.setHistoryExportState(false, false, "Container is gone")
+		return
+	}
+	if len(a.historyExportFile) == 0 || strings.ContainsRune(a.historyExportFile, os.PathSeparator) {
+		a.setHistoryExportState(false, false, "Enter a file name without directory")
+		return
+	}
+	path := filepath.Join(a.historyExportDir, a.historyExportFile)
+	format := a.historyExportFormat
+	// the export writes histories that must not change while they get written
+	containers = slices.Clone(containers)
+	for idx := range containers {
+		containers[idx].CloneHistories()
+	}
+	a.setHistoryExportState(true, false, "")
+	go func() {
+		if err := ExportHistory(path, format, containers); err != nil {
+			a.setHistoryExportState(false, false, fmt.Sprintf("Failed to export history: %v", err))
+		} else {
+			fmt.Printf("Exported history of %s to %s\n", name, path)
+			a.setHistoryExportState(false, true, "")
+		}
+		giu.Update()
+	}()
+}
+
+// historyExportDirsLayout lists the parent and sub directories of the export directory, clicking one browses it
+func (a *App) historyExportDirsLayout() giu.Widget {
+	return giu.Custom(func() {
+		dir := a.historyExportDir
+		if parent := filepath.Dir(dir); parent != dir {
+			giu.Selectable(".." + string(os.PathSeparator)).OnClick(func() {
+				a.browseHistoryExportDir(parent)
+			}).Build()
+		}
+		for _, sub := range a.historyExportSubDirs {
+			path := filepath.Join(dir, sub)
+			giu.Selectable(sub + string(os.PathSeparator)).OnClick(func() {
+				a.browseHistoryExportDir(path)
+			}).Build()
+		}
+	})
+}
+
+func (a *App) historyExportLayout() giu.Layout {
+	containers, name := a.historyExportContainers()
+	hasProject := len(containers) > 0 && len(containers[0].DockerComposeProject) > 0
+	exporting, saved, exportError := a.historyExportState()
+	if saved {
+		a.historyExportPopup.Close()
+	}
+	return giu.Layout{
+		giu.Label(fmt.Sprintf("Export metric history of %s", name)),
+		giu.Row(
+			giu.RadioButton("Container", !a.historyExportProject).OnChange(func() {
+				a.setHistoryExportOptions(false, a.historyExportFormat)
+			}),
+			giu.Condition(hasProject,
+				giu.Layout{giu.RadioButton("Compose project", a.historyExportProject).OnChange(func() {
+					a.setHistoryExportOptions(true, a.historyExportFormat)
+				})},
+				nil,
+			),
+		),
+		giu.Row(
+			giu.RadioButton("CSV", a.historyExportFormat == HistoryExportCSV).OnChange(func() {
+				a.setHistoryExportOptions(a.historyExportProject, HistoryExportCSV)
+			}),
+			giu.RadioButton("JSON lines", a.historyExportFormat == HistoryExportJSONL).OnChange(func() {
+				a.setHistoryExportOptions(a.historyExportProject, HistoryExportJSONL)
+			}),
+		),
+		giu.Label(fmt.Sprintf("Directory %s", a.historyExportDir)),
+		giu.Child().Border(true).Size(400, 200).Layout(
+			a.historyExportDirsLayout(),
+		),
+		giu.InputText(&a.historyExportFile).Label("File").Size(400),
+		giu.Condition(len(exportError) > 0, giu.Layout{giu.Label(exportError)}, nil),
+		giu.Condition(exporting,
+			giu.Layout{giu.Label("Exporting...")},
+			giu.Layout{giu.Row(
+				giu.Button("Save").OnClick(func() {
+					a.startHistoryExport(containers, name)
+				}),
+				giu.Button("Cancel").OnClick(func() {
+					a.historyExportPopup.Close()
+				}),
+			)},
+		),
+	}
+}
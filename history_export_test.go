@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistoryExportRecordsMergesTiersBeforeRawSamples(t *testing.T) {
+	history := History{
+		Samples: []Sample{{100, 1}, {101, 3}},
+		Tiers: []HistoryTier{
+			{Resolution: 10, Buckets: []Bucket{
+				{timestamp: 80, min: 1, max: 5, sum: 6, count: 2},
+				{timestamp: 90, min: 2, max: 2, sum: 2, count: 1},
+				{timestamp: 100, min: 1, max: 3, sum: 4, count: 2}, // covered by raw samples
+			}},
+			{Resolution: 60, Buckets: []Bucket{
+				{timestamp: 0, min: 4, max: 8, sum: 12, count: 2},
+				{timestamp: 60, min: 1, max: 5, sum: 8, count: 4}, // covered by the finer tier
+			}},
+		},
+	}
+
+	var actual []string
+	for _, record := range historyExportRecords(&history) {
+		actual = append(actual, strings.Join(record.csv(), ","))
+	}
+	expected := []string{
+		"1970-01-01T00:00:00Z,,,,,,1m,6,4,8",
+		"1970-01-01T00:01:20Z,,,,,,10s,3,1,5",
+		"1970-01-01T00:01:30Z,,,,,,10s,2,2,2",
+		"1970-01-01T00:01:40Z,,,,,,raw,1,1,1",
+		"1970-01-01T00:01:41Z,,,,,,raw,3,3,3",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected records\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestHistoryExportRecordsWithoutRawSamples(t *testing.T) {
+	history := History{
+		Tiers: []HistoryTier{
+			{Resolution: 10, Buckets: []Bucket{{timestamp: 60, min: 1, max: 1, sum: 1, count: 1}}},
+			{Resolution: 60, Buckets: []Bucket{{timestamp: 0, min: 2, max: 2, sum: 2, count: 1}, {timestamp: 60, min: 1, max: 1, sum: 1, count: 1}}},
+		},
+	}
+
+	records := historyExportRecords(&history)
+	if len(records) != 2 || records[0].Resolution != "1m" || records[1].Resolution != "10s" {
+		t.Errorf("expected the bucket of the coarse tier before the bucket of the fine tier, got %+v", records)
+	}
+}
+
+func TestHistoryExportTargets(t *testing.T) {
+	containers := []ContainerData{
+		{ID: "a1b2c3d4e5", Host: "local", Name: "shop-web-1", AlternativeName: "web-1", DockerComposeProject: "shop"},
+		{ID: "a1f0e9d8c7", Host: "local", Name: "shop-db-1", AlternativeName: "db-1", DockerComposeProject: "shop"},
+		{ID: "b7c6d5e4f3", Host: "local", Name: "blog-db-1", AlternativeName: "db-1", DockerComposeProject: "blog"},
+		{ID: "c9d8e7f6a5", Host: "remote", Name: "shop-web-1", AlternativeName: "web-1", DockerComposeProject: "shop"},
+		{ID: "d1e2f3a4b5", Host: "remote", Name: "a1b2", AlternativeName: "a1b2"},
+	}
+	tests := []struct {
+		target   string
+		expected []string // ids of the exported containers
+		err      bool
+	}{
+		{"shop-db-1", []string{"a1f0e9d8c7"}, false},
+		{"blog", []string{"b7c6d5e4f3"}, false},
+		{"b7c6", []string{"b7c6d5e4f3"}, false},
+		{"a1b2", []string{"d1e2f3a4b5"}, false}, // the name is preferred over an id prefix
+		{"a1", nil, false},                      // too short for an id prefix
+		{"a1f0e", []string{"a1f0e9d8c7"}, false},
+		{"web-1", nil, true},      // containers of several hosts
+		{"db-1", nil, true},       // services of several projects
+		{"shop", nil, true},       // projects of several hosts
+		{"shop-web-1", nil, true}, // containers of several hosts
+		{"unknown", nil, false},
+	}
+	for _, test := range tests {
+		containers, err := historyExportTargets(containers, test.target)
+		if (err != nil) != test.err {
+			t.Errorf("historyExportTargets(%q): unexpected error %v", test.target, err)
+			continue
+		}
+		var ids []string
+		for _, data := range containers {
+			ids = append(ids, data.ID)
+		}
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("historyExportTargets(%q): expected %v, got %v", test.target, test.expected, ids)
+		}
+	}
+}
+
+func TestHistoryExportTargetsOfProjectOnOneHost(t *testing.T) {
+	containers := []ContainerData{
+		{ID: "a1b2c3d4e5", Host: "local", Name: "shop-web-1", DockerComposeProject: "shop"},
+		{ID: "a1f0e9d8c7", Host: "local", Name: "shop-db-1", DockerComposeProject: "shop"},
+		{ID: "b7c6d5e4f3", Host: "local", Name: "blog-db-1", DockerComposeProject: "blog"},
+	}
+	matching, err := historyExportTargets(containers, "shop")
+	if err != nil || len(matching) != 2 {
+		t.Errorf("expected both containers of the project, got %d: %v", len(matching), err)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryMetric identifies a history series of a container in the history store
+type HistoryMetric byte
+
+const (
+	CpuPercentHistoryMetric          HistoryMetric = 1
+	CpuThrottledPercentHistoryMetric HistoryMetric = 2
+	MemoryHistoryMetric              HistoryMetric = 3
+	NetworkRxHistoryMetric           HistoryMetric = 4
+	NetworkTxHistoryMetric           HistoryMetric = 5
+	BlockReadHistoryMetric           HistoryMetric = 6
+	BlockWriteHistoryMetric          HistoryMetric = 7
+	PIDsHistoryMetric                HistoryMetric = 8
+)
+
+const (
+	historyFileMagic      = "CHH1"
+	historyFileSuffix     = ".hist"
+	historyRecordSize     = 1 + 8 + 8 // metric, timestamp, value
+	historyCompactionTime = time.Hour
+)
+
+// HistorySeries returns the history series of the container by metric
+func (d *ContainerData) HistorySeries() map[HistoryMetric]*History {
+	return map[HistoryMetric]*History{
+		CpuPercentHistoryMetric:          &d.CpuPercentHistory,
+		CpuThrottledPercentHistoryMetric: &d.CpuThrottledPercentHistory,
+		MemoryHistoryMetric:              &d.MemoryHistory,
+		NetworkRxHistoryMetric:           &d.NetworkRxHistory,
+		NetworkTxHistoryMetric:           &d.NetworkTxHistory,
+		BlockReadHistoryMetric:           &d.BlockReadHistory,
+		BlockWriteHistoryMetric:          &d.BlockWriteHistory,
+		PIDsHistoryMetric:                &d.PIDsHistory,
+	}
+}
+
+// CloneHistories replaces the history series by deep copies, e.g. to read them while the original ones get updated
+func (d *ContainerData) CloneHistories() {
+	for _, history := range d.HistorySeries() {
+		*history = history.Clone()
+	}
+}
+
+// HistoryStore persists history samples of containers in append-only files, one file per container.
+// Each file starts with a magic followed by fixed size records of metric, timestamp and value.
+type HistoryStore struct {
+	dir       string
+	retention time.Duration
+
+	mutex sync.Mutex
+	files map[string]*os.File
+}
+
+// OpenHistoryStore opens the history store in given directory, samples older than retention are dropped
+func OpenHistoryStore(dir string, retention time.Duration) (*HistoryStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	s := &HistoryStore{dir: dir, retention: retention, files: make(map[string]*os.File)}
+	if err := s.Compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// defaultHistoryDir returns the directory of the history store in the user's cache directory
+func defaultHistoryDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "container-hud", "history")
+}
+
+func (s *HistoryStore) path(id string) string {
+	return filepath.Join(s.dir, id+historyFileSuffix)
+}
+
+// Restore adds the persisted samples of the container to its history
+func (s *HistoryStore) Restore(data *ContainerData) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	content, err := os.ReadFile(s.path(data.ID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	series := data.HistorySeries()
+	oldest := float64(time.Now().Add(-s.retention).Unix())
+	return readHistoryRecords(content, func(metric HistoryMetric, sample Sample) {
+		if history, ok := series[metric]; ok && sample.timestamp >= oldest {
+			history.Add(sample)
+		}
+	})
+}
+
+// AppendLatest persists the samples of the latest stats reading of the container,
+// series without a sample of that reading (e.g. counters on the first reading) are skipped
+func (s *HistoryStore) AppendLatest(data *ContainerData) error {
+	var buffer bytes.Buffer
+	for metric, history := range data.HistorySeries() {
+		if len(history.Samples) > 0 && history.Samples[len(history.Samples)-1].timestamp == float64(data.LastUpdated) {
+			writeHistoryRecord(&buffer, metric, history.Samples[len(history.Samples)-1])
+		}
+	}
+	if buffer.Len() == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := s.open(data.ID)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(buffer.Bytes())
+	return err
+}
+
+// open returns the file of the container opened for appending, creates the file if required
+func (s *HistoryStore) open(id string) (*os.File, error) {
+	if file, ok := s.files[id]; ok {
+		return file, nil
+	}
+	file, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if stat, err := file.Stat(); err == nil && stat.Size() == 0 {
+		if _, err := file.WriteString(historyFileMagic); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	} else if err == nil && (stat.Size()-int64(len(historyFileMagic)))%historyRecordSize != 0 {
+		// drop a record truncated by a previous crash, appended records would be misaligned otherwise
+		size := stat.Size() - (stat.Size()-int64(len(historyFileMagic)))%historyRecordSize
+		if err := file.Truncate(size); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	s.files[id] = file
+	return file, nil
+}
+
+// Release closes the file of the container, it will be reopened by the next append
+func (s *HistoryStore) Release(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if file, ok := s.files[id]; ok {
+		_ = file.Close()
+		delete(s.files, id)
+	}
+}
+
+// Compact rewrites all files without samples older than retention, files without recent samples are removed
+func (s *HistoryStore) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+historyFileSuffix))
+	if err != nil {
+		return err
+	}
+	oldest := float64(time.Now().Add(-s.retention).Unix())
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), historyFileSuffix)
+		if file, ok := s.files[id]; ok {
+			_ = file.Close()
+			delete(s.files, id)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var buffer bytes.Buffer
+		buffer.WriteString(historyFileMagic)
+		err = readHistoryRecords(content, func(metric HistoryMetric, sample Sample) {
+			if sample.timestamp >= oldest {
+				writeHistoryRecord(&buffer, metric, sample)
+			}
+		})
+		if err != nil {
+			fmt.Printf("Dropping broken history file %s: %v\n", path, err)
+		}
+		if buffer.Len() == len(historyFileMagic) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		if buffer.Len() == len(content) {
+			continue
+		}
+		tmpPath := path + ".tmp"
+		if err := os.WriteFile(tmpPath, buffer.Bytes(), 0o600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompactPeriodically applies the retention policy until done is closed
+func (s *HistoryStore) CompactPeriodically(done <-chan struct{}) {
+	for {
+		select {
+		case <-time.After(historyCompactionTime):
+			if err := s.Compact(); err != nil {
+				fmt.Printf("Failed to compact history: %v\n", err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Close closes all open files of the store
+func (s *HistoryStore) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, file := range s.files {
+		_ = file.Close()
+		delete(s.files, id)
+	}
+}
+
+func writeHistoryRecord(w io.Writer, metric HistoryMetric, sample Sample) {
+	var record [historyRecordSize]byte
+	record[0] = byte(metric)
+	binary.LittleEndian.PutUint64(record[1:9], math.Float64bits(sample.timestamp))
+	binary.LittleEndian.PutUint64(record[9:17], math.Float64bits(sample.value))
+	_, _ = w.Write(record[:])
+}
+
+// readHistoryRecords calls given function for every record of given file content, a truncated last record is ignored
+func readHistoryRecords(content []byte, record func(metric HistoryMetric, sample Sample)) error {
+	if !bytes.HasPrefix(content, []byte(historyFileMagic)) {
+		return fmt.Errorf("missing magic %q", historyFileMagic)
+	}
+	for records := content[len(historyFileMagic):]; len(records) >= historyRecordSize; records = records[historyRecordSize:] {
+		record(HistoryMetric(records[0]), Sample{
+			timestamp: math.Float64frombits(binary.LittleEndian.Uint64(records[1:9])),
+			value:     math.Float64frombits(binary.LittleEndian.Uint64(records[9:17])),
+		})
+	}
+	return nil
+}
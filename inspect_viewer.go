@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/AllenDang/giu"
+	"github.com/AllenDang/imgui-go"
+	"golang.design/x/clipboard"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	InspectViewerWidth  = 600
+	InspectViewerHeight = 500
+)
+
+var (
+	InspectMatchColor = LogMatchColor
+
+	inspectIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+type inspectNodeKind int
+
+const (
+	inspectLeaf inspectNodeKind = iota
+	inspectObject
+	inspectArray
+)
+
+// InspectNode is a value of a JSON document, objects keep the order of their keys
+type InspectNode struct {
+	Key      string
+	Path     string // path of the value in jq syntax, e.g. .Mounts[0].Source
+	kind     inspectNodeKind
+	value    any // value of a leaf, i.e. string, json.Number, bool or nil
+	Children []*InspectNode
+}
+
+// parseInspectJSON parses a JSON document into a tree of nodes
+func parseInspectJSON(raw []byte) (*InspectNode, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return parseInspectValue(decoder, "", "")
+}
+
+func parseInspectValue(decoder *json.Decoder, key string, path string) (*InspectNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &InspectNode{Key: key, Path: path}
+	switch token {
+	case json.Delim('{'):
+		node.kind = inspectObject
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			childKey := keyToken.(string)
+			childPath := path + "." + childKey
+			if !inspectIdentifierPattern.MatchString(childKey) {
+				childPath = fmt.Sprintf("%s[%q]", path, childKey)
+			}
+			child, err := parseInspectValue(decoder, childKey, childPath)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	case json.Delim('['):
+		node.kind = inspectArray
+		for idx := 0; decoder.More(); idx++ {
+			child, err := parseInspectValue(decoder, fmt.Sprintf("[%d]", idx), fmt.Sprintf("%s[%d]", path, idx))
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	default:
+		node.value = token
+		return node, nil
+	}
+	// consume closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// JSONPath returns the path of the node, "." for the root node
+func (n *InspectNode) JSONPath() string {
+	if len(n.Path) == 0 {
+		return "."
+	}
+	return n.Path
+}
+
+// Text returns the value of a leaf as text, strings are not quoted
+func (n *InspectNode) Text() string {
+	if s, ok := n.value.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(n.value)
+	return string(encoded)
+}
+
+// JSON returns the value of the node as indented JSON
+func (n *InspectNode) JSON() string {
+	if n.kind == inspectLeaf {
+		return n.Text()
+	}
+	var buf bytes.Buffer
+	n.writeJSON(&buf)
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return buf.String()
+	}
+	return indented.String()
+}
+
+func (n *InspectNode) writeJSON(buf *bytes.Buffer) {
+	switch n.kind {
+	case inspectObject:
+		buf.WriteByte('{')
+		for idx, child := range n.Children {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(child.Key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			child.writeJSON(buf)
+		}
+		buf.WriteByte('}')
+	case inspectArray:
+		buf.WriteByte('[')
+		for idx, child := range n.Children {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			child.writeJSON(buf)
+		}
+		buf.WriteByte(']')
+	default:
+		encoded, _ := json.Marshal(n.value)
+		buf.Write(encoded)
+	}
+}
+
+// label returns the text shown for the node in the tree
+func (n *InspectNode) label() string {
+	switch n.kind {
+	case inspectObject:
+		return fmt.Sprintf("%s {%d}", n.Key, len(n.Children))
+	case inspectArray:
+		return fmt.Sprintf("%s [%d]", n.Key, len(n.Children))
+	default:
+		encoded, _ := json.Marshal(n.value)
+		return fmt.Sprintf("%s: %s", n.Key, encoded)
+	}
+}
+
+// matches returns true if the key or value of the node contains given lower case search text
+func (n *InspectNode) matches(search string) bool {
+	if strings.Contains(strings.ToLower(n.Key), search) {
+		return true
+	}
+	return n.kind == inspectLeaf && strings.Contains(strings.ToLower(n.Text()), search)
+}
+
+// InspectViewer is a window showing the inspect result of a container as searchable tree
+type InspectViewer struct {
+	windowId string
+	id       string // id of the inspected container
+	title    string
+	fetch    func(ctx context.Context, id string) ([]byte, error)
+
+	mutex sync.Mutex
+	root  *InspectNode
+	err   string
+
+	open   bool
+	search string
+}
+
+// NewInspectViewer creates an open inspect viewer of the container with given id, fetch returns its inspect result
+func NewInspectViewer(windowId string, id string, title string, fetch func(ctx context.Context, id string) ([]byte, error)) *InspectViewer {
+	return &InspectViewer{
+		windowId: windowId,
+		id:       id,
+		title:    title,
+		fetch:    fetch,
+		open:     true,
+	}
+}
+
+// Refresh fetches the inspect result of the container
+func (v *InspectViewer) Refresh() {
+	raw, err := v.fetch(context.Background(), v.id)
+	var root *InspectNode
+	if err == nil {
+		root, err = parseInspectJSON(raw)
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if err != nil {
+		v.err = fmt.Sprintf("Failed to inspect container: %v", err)
+	} else {
+		v.root = root
+		v.err = ""
+	}
+
+	giu.Update()
+}
+
+// IsOpen returns false when the user closed the window
+func (v *InspectViewer) IsOpen() bool {
+	return v.open
+}
+
+// visible returns true if the node or any of its descendants matches the search
+func (v *InspectViewer) visible(node *InspectNode, search string) bool {
+	if node.matches(search) {
+		return true
+	}
+	for _, child := range node.Children {
+		if v.visible(child, search) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderNode builds the tree of given node, all descendants of a node matching the search are shown
+func (v *InspectViewer) renderNode(node *InspectNode, search string) {
+	matched := len(search) > 0 && node.matches(search)
+	if len(search) > 0 && !matched && !v.visible(node, search) {
+		return
+	}
+	if matched {
+		search = ""
+	}
+
+	label := fmt.Sprintf("%s##%s", node.label(), node.JSONPath())
+	if node.kind == inspectLeaf {
+		widget := giu.Widget(giu.Selectable(label))
+		if matched {
+			widget = giu.Style().SetColor(giu.StyleColorText, InspectMatchColor).To(widget)
+		}
+		widget.Build()
+		v.contextMenu(node).Build()
+		return
+	}
+
+	if len(search) > 0 {
+		imgui.SetNextItemOpen(true, imgui.ConditionAlways)
+	}
+	if matched {
+		imgui.PushStyleColor(imgui.StyleColorText, giu.ToVec4Color(InspectMatchColor))
+	}
+	open := imgui.TreeNodeV(label, 0)
+	if matched {
+		imgui.PopStyleColor()
+	}
+	v.contextMenu(node).Build()
+	if open {
+		for _, child := range node.Children {
+			v.renderNode(child, search)
+		}
+		imgui.TreePop()
+	}
+}
+
+// contextMenu offers to copy the value or path of the node
+func (v *InspectViewer) contextMenu(node *InspectNode) giu.Widget {
+	return giu.ContextMenu().ID("copy"+node.JSONPath()).Layout(
+		giu.Selectable("Copy value").OnClick(func() {
+			fmt.Printf("Copied value of %s to clipboard\n", node.JSONPath())
+			clipboard.Write(clipboard.FmtText, []byte(node.JSON()))
+		}),
+		giu.Selectable("Copy path").OnClick(func() {
+			fmt.Printf("Copied path %s to clipboard\n", node.JSONPath())
+			clipboard.Write(clipboard.FmtText, []byte(node.JSONPath()))
+		}),
+	)
+}
+
+// Render builds the window of the inspect viewer
+func (v *InspectViewer) Render() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	giu.Window(fmt.Sprintf("%s##inspect%s", v.title, v.windowId)).IsOpen(&v.open).Size(InspectViewerWidth, InspectViewerHeight).Layout(
+		giu.Row(
+			giu.Button("Refresh").OnClick(func() {
+				go v.Refresh()
+			}),
+			giu.InputText(&v.search).Hint("Search keys and values").Size(250),
+			giu.Label("Right-click to copy value or path"),
+		),
+		giu.Condition(len(v.err) > 0, giu.Layout{giu.Label(v.err)}, nil),
+		giu.Child().Border(true).Flags(giu.WindowFlagsHorizontalScrollbar).Layout(
+			giu.Custom(func() {
+				if v.root == nil {
+					return
+				}
+				search := strings.ToLower(v.search)
+				for _, child := range v.root.Children {
+					v.renderNode(child, search)
+				}
+			}),
+		),
+	)
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/AllenDang/giu"
+	"github.com/AllenDang/imgui-go"
+	"image/color"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxLogLines is the number of lines kept by a log viewer, older lines are dropped
+	MaxLogLines = 10000
+
+	LogViewerWidth  = 800
+	LogViewerHeight = 400
+
+	// LogUpdateInterval is the minimum interval between redraws requested by appended log lines
+	LogUpdateInterval = 100 * time.Millisecond
+)
+
+var (
+	LogErrorColor  = color.RGBA{R: 255, G: 90, B: 90, A: 255}
+	LogWarnColor   = color.RGBA{R: 255, G: 200, B: 60, A: 255}
+	LogMatchColor  = color.RGBA{R: 90, G: 255, B: 90, A: 255}
+	LogStderrColor = color.RGBA{R: 255, G: 170, B: 170, A: 255}
+
+	LogSourceColors = []color.RGBA{
+		{R: 90, G: 200, B: 255, A: 255},
+		{R: 255, G: 130, B: 255, A: 255},
+		{R: 130, G: 255, B: 200, A: 255},
+		{R: 255, G: 180, B: 120, A: 255},
+		{R: 180, G: 160, B: 255, A: 255},
+		{R: 220, G: 255, B: 120, A: 255},
+	}
+
+	logErrorPattern = regexp.MustCompile(`(?i)\b(error|err|fatal|panic|critical)\b`)
+	logWarnPattern  = regexp.MustCompile(`(?i)\b(warn|warning)\b`)
+)
+
+// logEntry is a log line with its level color and whether it matches the search
+type logEntry struct {
+	LogLine
+	color color.Color
+	match bool
+}
+
+// LogViewer is a window showing the streamed log lines of a container or merged log lines of several containers
+type LogViewer struct {
+	id    string
+	title string
+
+	mutex         sync.Mutex
+	lines         []logEntry
+	visible       []int // indexes of lines passing the filters, ascending
+	dropped       int
+	updatePending bool
+	ended         string
+	sources       []string        // names of containers of merged logs, in order of appearance
+	muted         map[string]bool // sources whose lines are hidden
+
+	cancel context.CancelFunc
+
+	open          bool
+	follow        bool
+	showTimestamp bool
+	onlyMatching  bool
+	search        string
+	searchPattern *regexp.Regexp
+	searchError   string
+}
+
+// NewLogViewer creates an open log viewer, cancel is called when the viewer gets closed
+func NewLogViewer(id string, title string, cancel context.CancelFunc) *LogViewer {
+	return &LogViewer{
+		id:     id,
+		title:  title,
+		cancel: cancel,
+		open:   true,
+		follow: true,
+		muted:  make(map[string]bool),
+	}
+}
+
+// Append adds a log line ordered by its timestamp, the oldest line is dropped if the buffer is full
+func (v *LogViewer) Append(line LogLine) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if len(v.lines) >= MaxLogLines {
+		drop := len(v.lines) - MaxLogLines + 1
+		copy(v.lines, v.lines[drop:])
+		v.lines = v.lines[:len(v.lines)-drop]
+		v.dropped += drop
+		// forget the dropped lines and shift the remaining indexes
+		first := sort.SearchInts(v.visible, drop)
+		v.visible = slices.Delete(v.visible, 0, first)
+		for i := range v.visible {
+			v.visible[i] -= drop
+		}
+	}
+	idx := sort.Search(len(v.lines), func(i int) bool {
+		return v.lines[i].Timestamp.After(line.Timestamp)
+	})
+	entry := logEntry{LogLine: line, color: levelColor(line), match: v.matches(line)}
+	v.lines = slices.Insert(v.lines, idx, entry)
+	pos := sort.SearchInts(v.visible, idx)
+	for i := pos; i < len(v.visible); i++ {
+		v.visible[i]++
+	}
+	if v.isVisible(entry) {
+		v.visible = slices.Insert(v.visible, pos, idx)
+	}
+	if len(line.Source) > 0 && !slices.Contains(v.sources, line.Source) {
+		v.sources = append(v.sources, line.Source)
+	}
+
+	v.scheduleUpdate()
+}
+
+// scheduleUpdate requests a redraw, lines appended in quick succession are drawn by a single redraw
+func (v *LogViewer) scheduleUpdate() {
+	if v.updatePending {
+		return
+	}
+	v.updatePending = true
+	time.AfterFunc(LogUpdateInterval, func() {
+		v.mutex.Lock()
+		v.updatePending = false
+		v.mutex.Unlock()
+		giu.Update()
+	})
+}
+
+// End marks the end of the log stream, err is shown if set
+func (v *LogViewer) End(err error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if err != nil {
+		v.ended = fmt.Sprintf("Log stream ended: %v", err)
+	} else {
+		v.ended = "Log stream ended"
+	}
+
+	giu.Update()
+}
+
+// Close stops streaming of logs
+func (v *LogViewer) Close() {
+	v.cancel()
+}
+
+// IsOpen returns false when the user closed the window
+func (v *LogViewer) IsOpen() bool {
+	return v.open
+}
+
+func (v *LogViewer) updateSearch() {
+	v.searchPattern = nil
+	v.searchError = ""
+	if len(v.search) > 0 {
+		pattern, err := regexp.Compile(v.search)
+		if err != nil {
+			v.searchError = err.Error()
+		} else {
+			v.searchPattern = pattern
+		}
+	}
+	for i := range v.lines {
+		v.lines[i].match = v.matches(v.lines[i].LogLine)
+	}
+	v.updateVisible()
+}
+
+// matches returns true if the line matches the search
+func (v *LogViewer) matches(line LogLine) bool {
+	return v.searchPattern != nil && v.searchPattern.MatchString(line.Text)
+}
+
+// isVisible returns true if the line passes the search and source filters
+func (v *LogViewer) isVisible(entry logEntry) bool {
+	if v.onlyMatching && v.searchPattern != nil && !entry.match {
+		return false
+	}
+	return !v.muted[entry.Source]
+}
+
+// updateVisible collects the indexes of lines passing the filters
+func (v *LogViewer) updateVisible() {
+	v.visible = v.visible[:0]
+	for i, entry := range v.lines {
+		if v.isVisible(entry) {
+			v.visible = append(v.visible, i)
+		}
+	}
+}
+
+// sourceColor returns the color of the name of given source
+func (v *LogViewer) sourceColor(source string) color.Color {
+	return LogSourceColors[slices.Index(v.sources, source)%len(LogSourceColors)]
+}
+
+// levelColor returns the color of a log line by its log level
+func levelColor(line LogLine) color.Color {
+	switch {
+	case logErrorPattern.MatchString(line.Text):
+		return LogErrorColor
+	case logWarnPattern.MatchString(line.Text):
+		return LogWarnColor
+	case line.Stderr:
+		return LogStderrColor
+	default:
+		return nil
+	}
+}
+
+// lineWidget returns the widget of a log line, matches of the search take precedence over log levels
+func (v *LogViewer) lineWidget(entry logEntry) giu.Widget {
+	text := entry.Text
+	if v.showTimestamp {
+		text = fmt.Sprintf("%s %s", entry.Timestamp.Local().Format("15:04:05.000"), text)
+	}
+	var widget giu.Widget = giu.Label(text)
+	c := entry.color
+	if entry.match {
+		c = LogMatchColor
+	}
+	if c != nil {
+		widget = giu.Style().SetColor(giu.StyleColorText, c).To(widget)
+	}
+	if len(entry.Source) > 0 {
+		widget = giu.Row(
+			giu.Style().SetColor(giu.StyleColorText, v.sourceColor(entry.Source)).To(giu.Label(entry.Source+" |")),
+			widget,
+		)
+	}
+	return widget
+}
+
+// Render builds the window of the log viewer
+func (v *LogViewer) Render() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	var status string
+	if v.dropped > 0 {
+		status = fmt.Sprintf("%d lines, %d dropped", len(v.lines), v.dropped)
+	} else {
+		status = fmt.Sprintf("%d lines", len(v.lines))
+	}
+
+	giu.Window(fmt.Sprintf("%s##%s", v.title, v.id)).IsOpen(&v.open).Size(LogViewerWidth, LogViewerHeight).Layout(
+		giu.Row(
+			giu.Checkbox("Follow", &v.follow),
+			giu.Checkbox("Timestamps", &v.showTimestamp),
+			giu.InputText(&v.search).Hint("Search regex").Size(200).OnChange(v.updateSearch),
+			giu.Checkbox("Only matching", &v.onlyMatching).OnChange(v.updateVisible),
+			giu.Label(status),
+		),
+		giu.Condition(len(v.sources) > 0,
+			giu.Layout{
+				giu.Custom(func() {
+					giu.Label("Show").Build()
+					for _, source := range v.sources {
+						name := source
+						show := !v.muted[name]
+						giu.SameLine()
+						giu.Style().SetColor(giu.StyleColorText, v.sourceColor(name)).To(
+							giu.Checkbox(name, &show).OnChange(func() {
+								v.muted[name] = !show
+								v.updateVisible()
+							}),
+						).Build()
+					}
+				}),
+			},
+			nil,
+		),
+		giu.Condition(len(v.searchError) > 0, giu.Layout{giu.Label(v.searchError)}, nil),
+		giu.Condition(len(v.ended) > 0, giu.Layout{giu.Label(v.ended)}, nil),
+		giu.Child().Border(true).Flags(giu.WindowFlagsHorizontalScrollbar).Layout(
+			giu.Custom(func() {
+				// build widgets of the lines scrolled into view only
+				clipper := imgui.NewListClipper()
+				defer clipper.Delete()
+				clipper.Begin(len(v.visible))
+				for clipper.Step() {
+					for i := clipper.DisplayStart(); i < clipper.DisplayEnd(); i++ {
+						v.lineWidget(v.lines[v.visible[i]]).Build()
+					}
+				}
+				clipper.End()
+				if v.follow {
+					imgui.SetScrollHereY(1)
+				}
+			}),
+		),
+	)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	types_container "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// LogTailLines is the number of past log lines fetched when starting to follow logs
+	LogTailLines = "1000"
+
+	maxLogLineLength = 1024 * 1024
+
+	// projectLogsRetries is the number of attempts to follow the logs of a started container that is not known yet
+	projectLogsRetries    = 5
+	projectLogsRetryDelay = 500 * time.Millisecond
+)
+
+// LogLine is a single line logged by a container
+type LogLine struct {
+	Timestamp time.Time
+	Stderr    bool
+	Text      string
+	Source    string // name of the logging container in logs merged from several containers
+}
+
+// followContainerLogs calls given function for every log line of the container until ctx is done or the container exited.
+// Stdout and stderr are demultiplexed unless the container has a TTY.
+func followContainerLogs(ctx context.Context, backend ContainerBackend, id string, tail string, line func(LogLine)) error {
+	inspect, err := backend.ContainerInspect(ctx, id)
+	if err != nil {
+		return err
+	}
+	reader, err := backend.ContainerLogs(ctx, id, types_container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Tail:       tail,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	if inspect.Config != nil && inspect.Config.Tty {
+		return scanLogLines(reader, false, line)
+	}
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(stdoutWriter, stderrWriter, reader)
+		_ = stdoutWriter.CloseWithError(err)
+		_ = stderrWriter.CloseWithError(err)
+	}()
+	stderrDone := make(chan error, 1)
+	go func() {
+		stderrDone <- scanLogLines(stderrReader, true, line)
+	}()
+	err = scanLogLines(stdoutReader, false, line)
+	if stderrErr := <-stderrDone; err == nil {
+		err = stderrErr
+	}
+	return err
+}
+
+// scanLogLines calls given function for every line read, lines are expected to start with a timestamp
+func scanLogLines(reader io.Reader, stderr bool, line func(LogLine)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineLength)
+	for scanner.Scan() {
+		line(parseLogLine(scanner.Text(), stderr))
+	}
+	return scanner.Err()
+}
+
+// parseLogLine splits the timestamp added by the runtime from the logged text
+func parseLogLine(text string, stderr bool) LogLine {
+	text = strings.TrimRight(text, "\r")
+	if prefix, rest, ok := strings.Cut(text, " "); ok {
+		if timestamp, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+			return LogLine{Timestamp: timestamp, Stderr: stderr, Text: rest}
+		}
+	}
+	return LogLine{Timestamp: time.Now(), Stderr: stderr, Text: text}
+}
+
+// ProjectLogs follows the logs of the containers of a compose project,
+// containers started later are followed when their start gets notified
+type ProjectLogs struct {
+	host    string
+	project string
+	follow  func(ctx context.Context, id string, tail string, line func(LogLine)) error
+	line    func(LogLine)
+	ended   func(id string, err error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex     sync.Mutex
+	following map[string]bool      // containers whose logs are streamed
+	latest    map[string]time.Time // timestamp of the latest line of each container, a restarted container doesn't repeat lines
+}
+
+var _ NotificationChannel = &ProjectLogs{}
+
+// NewProjectLogs creates a follower of the logs of the compose project, line is called for every log line
+// and ended when the logs of a container ended with an error
+func NewProjectLogs(host string, project string,
+	follow func(ctx context.Context, id string, tail string, line func(LogLine)) error,
+	line func(LogLine), ended func(id string, err error)) *ProjectLogs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ProjectLogs{
+		host:      host,
+		project:   project,
+		follow:    follow,
+		line:      line,
+		ended:     ended,
+		ctx:       ctx,
+		cancel:    cancel,
+		following: make(map[string]bool),
+		latest:    make(map[string]time.Time),
+	}
+}
+
+// Follow starts streaming the logs of the container unless they are streamed already, source names the container
+func (p *ProjectLogs) Follow(id string, source string) {
+	p.followWithRetries(id, source, 1)
+}
+
+func (p *ProjectLogs) followWithRetries(id string, source string, attempts int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.following[id] || p.ctx.Err() != nil {
+		return
+	}
+	p.following[id] = true
+	since := p.latest[id]
+	go func() {
+		var err error
+		for attempt := 1; ; attempt++ {
+			err = p.follow(p.ctx, id, LogTailLines, func(line LogLine) {
+				if !line.Timestamp.After(since) {
+					return
+				}
+				p.mutex.Lock()
+				if line.Timestamp.After(p.latest[id]) {
+					p.latest[id] = line.Timestamp
+				}
+				p.mutex.Unlock()
+				line.Source = source
+				p.line(line)
+			})
+			if err == nil || attempt >= attempts || p.ctx.Err() != nil {
+				break
+			}
+			select {
+			case <-time.After(projectLogsRetryDelay):
+			case <-p.ctx.Done():
+			}
+		}
+		p.mutex.Lock()
+		delete(p.following, id)
+		p.mutex.Unlock()
+		if err != nil && p.ctx.Err() == nil {
+			p.ended(id, err)
+		}
+	}()
+}
+
+// Notify follows containers of the project when they start
+func (p *ProjectLogs) Notify(notification Notification) {
+	if notification.Kind != NotifyStart || notification.Host != p.host || notification.ComposeProject != p.project {
+		return
+	}
+	// the container may not be known yet when its start gets notified
+	p.followWithRetries(notification.ContainerID, notification.ContainerName, projectLogsRetries)
+}
+
+// Close stops streaming of logs
+func (p *ProjectLogs) Close() {
+	p.cancel()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProjectLogsFollowsStartedContainers(t *testing.T) {
+	start := time.Now()
+	var mutex sync.Mutex
+	var texts []string
+	logged := map[string][]LogLine{
+		"c1": {{Timestamp: start, Text: "web ready"}},
+		"c2": {{Timestamp: start.Add(time.Second), Text: "db ready"}},
+	}
+	follow := func(ctx context.Context, id string, tail string, line func(LogLine)) error {
+		mutex.Lock()
+		lines := slices.Clone(logged[id])
+		mutex.Unlock()
+		for _, l := range lines {
+			line(l)
+		}
+		return nil
+	}
+	logs := NewProjectLogs("local", "shop", follow, func(line LogLine) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		texts = append(texts, line.Source+": "+line.Text)
+	}, func(id string, err error) {
+		t.Errorf("unexpected error following %s: %v", id, err)
+	})
+	defer logs.Close()
+	received := func(n int) func() bool {
+		return func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(texts) == n
+		}
+	}
+
+	logs.Follow("c1", "web")
+	waitFor(t, "logs of followed container", received(1))
+
+	logs.Notify(Notification{Kind: NotifyStart, Host: "local", ComposeProject: "other", ContainerID: "c3"})
+	logs.Notify(Notification{Kind: NotifyStart, Host: "remote", ComposeProject: "shop", ContainerID: "c3"})
+	logs.Notify(Notification{Kind: NotifyStart, Host: "local", ComposeProject: "shop", ContainerID: "c2", ContainerName: "db"})
+	waitFor(t, "logs of started container", received(2))
+
+	waitFor(t, "streams to end", func() bool {
+		logs.mutex.Lock()
+		defer logs.mutex.Unlock()
+		return len(logs.following) == 0
+	})
+	// a restarted container repeats its tail, only new lines are shown
+	mutex.Lock()
+	logged["c1"] = append(logged["c1"], LogLine{Timestamp: start.Add(2 * time.Second), Text: "web restarted"})
+	mutex.Unlock()
+	logs.Notify(Notification{Kind: NotifyStart, Host: "local", ComposeProject: "shop", ContainerID: "c1", ContainerName: "web"})
+	waitFor(t, "logs of restarted container", received(3))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []string{"web: web ready", "db: db ready", "web: web restarted"}
+	if !slices.Equal(texts, expected) {
+		t.Errorf("expected lines %v, got %v", expected, texts)
+	}
+}
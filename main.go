@@ -2,14 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	types_container "github.com/docker/docker/api/types/container"
-	types_event "github.com/docker/docker/api/types/events"
-	"github.com/docker/docker/client"
 	"golang.design/x/clipboard"
-	"strconv"
+	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -21,241 +21,407 @@ var (
 )
 
 var (
-	app                *App = nil
-	containerInfo           = make(map[string]*ContainerInfo, 0)
-	containerInfoMutex      = sync.RWMutex{}
+	app            *App          = nil
+	historyStore   *HistoryStore = nil
+	collectors                   = make([]*Collector, 0)
+	collectorMutex               = sync.RWMutex{}
+
+	// daemons selectable in the daemon menu, selecting one switches the first collector
+	daemons        = make([]Endpoint, 0)
+	daemonSelected = ""
+	daemonMutex    = sync.Mutex{}
+
+	alertEngine = NewAlertEngine()
+
+	// RefreshInterval is the default interval container data is sent to the UI in, see Config
+	RefreshInterval = 1 * time.Second
+
+	// command executed by "Open shell"
+	execCommand = []string{"/bin/sh"}
+
+	// currentTime returns the time of the container data, it is the time of the replay clock while replaying a recording
+	currentTime = time.Now
 )
 
-func getDockerStats(ctx context.Context) chan bool {
-	done := make(chan bool, 1)
+// endpointsFlag collects endpoints given by repeated command-line flags
+type endpointsFlag []Endpoint
+
+func (f *endpointsFlag) String() string {
+	return fmt.Sprintf("%v", *f)
+}
 
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+func (f *endpointsFlag) Set(value string) error {
+	endpoint, err := ParseEndpoint(value)
 	if err != nil {
-		fmt.Printf("Failed to get docker client: %v\n", err)
-		defer close(done)
+		return err
+	}
+	// names tell hosts apart in the UI and in MQTT topics
+	if err := uniqueEndpointName(&endpoint, *f); err != nil {
+		return err
 	}
+	*f = append(*f, endpoint)
+	return nil
+}
 
-	// handle container info is sent through the channel and we will start following container stats
-	newContainerIds := make(chan string, 1)
-	go func() {
-		for id := range newContainerIds {
-			// local copy of newContainer struct
-			if _, ok := containerInfo[id]; !ok {
-				containerInfoMutex.Lock()
-				info := NewContainerInfo(id)
-				if inspect, err := cli.ContainerInspect(ctx, id); err == nil {
-					compose_project_dir := inspect.Config.Labels["com.docker.compose.project.working_dir"]
-					container_number := 1
-					if i, err := strconv.Atoi(inspect.Config.Labels["com.docker.compose.container-number"]); err == nil {
-						container_number = i
-					}
-					if created, err := time.Parse("2006-01-02T15:04:05.000000000Z", inspect.ContainerJSONBase.State.StartedAt); err == nil {
-						info.Data.Created = created.Unix()
-					}
-					info.Data.State = ContainerRunning
-					info.Data.Name = strings.TrimLeft(inspect.Name, "/")
-					info.Data.Image = inspect.Image
-					info.Data.DockerComposeProject = inspect.Config.Labels["com.docker.compose.project"]
-					info.Data.DockerComposeProjectDir = compose_project_dir
-					info.Data.DockerComposeService = inspect.Config.Labels["com.docker.compose.service"]
-					info.Data.DockerComposeContainerNumber = container_number
-					info.Data.SetAlternativeName()
-				} else {
-					fmt.Printf("Failed to inspect container %s: %v", info.Data.ID, err)
-				}
-
-				statsCtx, statsCancel := context.WithCancel(context.Background())
-				info.OnStopped = func() {
-					statsCancel()
-					containerInfoMutex.Lock()
-					defer containerInfoMutex.Unlock()
-					delete(containerInfo, info.Data.ID)
-				}
-				info.Stop = func() {
-					info.mutex.Lock()
-					if info.Data.State == ContainerRunning {
-						info.Data.State = ContainerStopping
-						info.mutex.Unlock()
-						fmt.Printf("Stopping container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
-						err := cli.ContainerStop(ctx, info.Data.ID, types_container.StopOptions{})
-						if err != nil {
-							fmt.Printf("Failed to stop container %s (%s): %v", info.Data.AlternativeName, info.Data.ID, err)
-						}
-					} else {
-						info.mutex.Unlock()
-					}
-				}
-				info.Restart = func() {
-					info.mutex.Lock()
-					defer info.mutex.Unlock()
-					if info.Data.State == ContainerRunning {
-						info.Data.State = ContainerRestarting
-						fmt.Printf("Restarting container %s (%s)...\n", info.Data.AlternativeName, info.Data.ID)
-						err := cli.ContainerRestart(ctx, info.Data.ID, types_container.StopOptions{})
-						if err != nil {
-							fmt.Printf("Failed to restart container %s (%s): %v", info.Data.AlternativeName, info.Data.ID, err)
-						}
-					}
-				}
-				fmt.Printf("Following container: %s (%s)\n", info.Data.AlternativeName, info.Data.ID)
-				containerInfo[id] = info
-				go updateContainerStats(statsCtx, cli, info)
-				containerInfoMutex.Unlock()
-
-				go func() {
-					for {
-						select {
-						case <-ctx.Done():
-							// outer context closed, stop stats context too
-							statsCancel()
-						case <-statsCtx.Done():
-							// stats context closed, we're done
-							return
-						case <-time.After(1 * time.Second):
-							//
-						}
-					}
-				}()
-			}
+// findContainer returns the info of container with given id from any collector
+func findContainer(id string) (*ContainerInfo, bool) {
+	collectorMutex.RLock()
+	defer collectorMutex.RUnlock()
+	for _, collector := range collectors {
+		if info, ok := collector.Container(id); ok {
+			return info, true
 		}
-	}()
+	}
+	return nil, false
+}
 
-	// listen to docker events related to starting & stopping containers
-	events, _ := cli.Events(ctx, types_event.ListOptions{})
-	go func() {
-		for event := range events {
-			fmt.Printf("Container Event: %s %s %s\n", event.Type, event.Status, event.Action)
-			if event.Type == "container" {
-				if event.Action == "start" {
-					fmt.Printf("Container started: %s\n", event.Actor.ID)
-					newContainerIds <- event.Actor.ID
-				}
-				if event.Action == "stop" || event.Action == "destroy" {
-					if info, ok := containerInfo[event.Actor.ID]; ok {
-						fmt.Printf("Container stopped: %s (%s)\n", info.Data.AlternativeName, event.Actor.ID)
-						info.mutex.Lock()
-						info.Data.State = ContainerStopped
-						info.mutex.Unlock()
-						info.OnStopped()
-					}
-				}
-			}
-		}
-	}()
+func restartContainer(id string) {
+	if info, ok := findContainer(id); ok {
+		info.Restart()
+	}
+}
 
-	// get currently running containers too
-	containers, err := cli.ContainerList(ctx, types_container.ListOptions{})
-	if err != nil {
-		fmt.Printf("Failed to get running containers: %v\n", err)
-		close(done)
-		return done
+func startContainer(id string) {
+	if info, ok := findContainer(id); ok {
+		info.Start()
 	}
-	for i := range containers {
-		fmt.Printf("Container is running: %s\n", containers[i].ID)
-		newContainerIds <- containers[i].ID
+}
+
+func pauseContainer(id string) {
+	if info, ok := findContainer(id); ok {
+		info.Pause()
 	}
+}
 
-	go func() {
-		for {
-			select {
-			case <-time.After(5 * time.Second):
-				// signal done if docker server is not available
-				ping, err := cli.Ping(ctx)
-				if err != nil || len(ping.APIVersion) == 0 {
-					fmt.Printf("Ping docker server failed: %v\n", err)
-					close(done)
-					return
-				}
-				fmt.Println("Ping docker server ok")
-			case <-ctx.Done():
-				close(done)
-				return
-			}
-		}
-	}()
+func unpauseContainer(id string) {
+	if info, ok := findContainer(id); ok {
+		info.Unpause()
+	}
+}
 
-	return done
+func killContainer(id string, signal string) {
+	if info, ok := findContainer(id); ok {
+		info.Kill(signal)
+	}
 }
 
-func getDockerStatsWithRetry(ctx context.Context) {
-	retryAfter := 5 * time.Second
-	go func() {
-		for {
-			fmt.Println("Following docker stats...")
-			statsCtx, cancel := context.WithCancel(context.Background())
-			done := getDockerStats(statsCtx)
-			select {
-			case <-done:
-				fmt.Printf("Retrying to follow docker stats in %s...\n", retryAfter)
-				containerInfoMutex.Lock()
-				containerInfo = make(map[string]*ContainerInfo, 0)
-				containerInfoMutex.Unlock()
-				cancel()
-				time.Sleep(retryAfter)
-			case <-ctx.Done():
-				cancel()
-				return
-			}
-		}
-	}()
+// followLogs calls given function for every log line of the container until ctx is done or the container exited
+func followLogs(ctx context.Context, id string, tail string, line func(LogLine)) error {
+	info, ok := findContainer(id)
+	if !ok {
+		return fmt.Errorf("unknown container %s", id)
+	}
+	return info.Logs(ctx, tail, line)
 }
 
-func restartContainer(id string) {
-	containerInfoMutex.RLock()
-	if info, ok := containerInfo[id]; ok {
-		containerInfoMutex.RUnlock()
-		info.Restart()
-	} else {
-		containerInfoMutex.RUnlock()
+// fetchInspect returns the inspect result of the container as JSON
+func fetchInspect(ctx context.Context, id string) ([]byte, error) {
+	info, ok := findContainer(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown container %s", id)
+	}
+	return info.Inspect(ctx)
+}
+
+// listProcesses returns the processes running in the container
+func listProcesses(ctx context.Context, id string) ([]Process, error) {
+	info, ok := findContainer(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown container %s", id)
+	}
+	return info.Processes(ctx)
+}
+
+func signalProcess(id string, pid int, signal string) {
+	if info, ok := findContainer(id); ok {
+		info.SignalProcess(pid, signal)
+	}
+}
+
+// execContainer executes the shell command in the container with a TTY of given size
+func execContainer(ctx context.Context, id string, rows int, cols int) (*ExecSession, error) {
+	info, ok := findContainer(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown container %s", id)
 	}
+	return info.Exec(ctx, execCommand, rows, cols)
 }
 
 func stopContainer(id string) {
-	containerInfoMutex.RLock()
-	if info, ok := containerInfo[id]; ok {
-		containerInfoMutex.RUnlock()
+	if info, ok := findContainer(id); ok {
 		info.Stop()
-	} else {
-		containerInfoMutex.RUnlock()
 	}
 }
 
-func sendContainerDataToApp() {
-	containerInfoMutex.RLock()
-	defer containerInfoMutex.RUnlock()
+// reloadDaemons lists the docker contexts and discovered podman sockets in the daemon menu
+func reloadDaemons() {
+	daemonMutex.Lock()
+	defer daemonMutex.Unlock()
+
+	daemons = make([]Endpoint, 0)
+	contexts, err := listDockerContexts()
+	if err != nil {
+		fmt.Printf("Failed to list docker contexts: %v\n", err)
+	}
+	for _, context := range contexts {
+		daemons = append(daemons, context.Endpoint())
+	}
+	for _, socket := range discoverPodmanSockets() {
+		daemons = append(daemons, Endpoint{Name: podmanDaemonName(socket), Host: "unix://" + socket, Backend: "podman"})
+	}
+
+	sendDaemonsToApp()
+}
+
+func sendDaemonsToApp() {
+	data := make([]DaemonData, len(daemons))
+	for i, daemon := range daemons {
+		data[i] = DaemonData{Name: daemon.Name, Host: daemon.Host}
+	}
+	app.Daemons(data, daemonSelected)
+}
+
+// selectDaemon switches the first collector to the daemon of given name
+func selectDaemon(name string) {
+	daemonMutex.Lock()
+	defer daemonMutex.Unlock()
+
+	for _, daemon := range daemons {
+		if daemon.Name != name {
+			continue
+		}
+		collectorMutex.RLock()
+		for _, other := range collectors[min(1, len(collectors)):] {
+			if other.Host() == daemon.Name {
+				fmt.Printf("Unable to select daemon %s, another host is named %q\n", daemon, daemon.Name)
+				collectorMutex.RUnlock()
+				return
+			}
+		}
+		if len(collectors) > 0 {
+			fmt.Printf("Selected daemon %s\n", daemon)
+			collectors[0].SwitchBackend(daemon.Name, recordingBackendFactory(daemon.Name, daemon.BackendFactory()))
+			daemonSelected = daemon.Name
+		}
+		collectorMutex.RUnlock()
+		sendDaemonsToApp()
+		return
+	}
+}
+
+// collectContainerData returns a snapshot of the containers and hosts of all collectors
+func collectContainerData() ([]ContainerData, []HostData) {
+	collectorMutex.RLock()
+	defer collectorMutex.RUnlock()
 
-	data := make([]ContainerData, len(containerInfo))
-	i := 0
-	for _, info := range containerInfo {
-		info.mutex.RLock()
-		data[i] = info.Data
-		info.mutex.RUnlock()
-		i++
+	data := make([]ContainerData, 0)
+	hosts := make([]HostData, 0, len(collectors))
+	for _, collector := range collectors {
+		data = append(data, collector.ContainerData()...)
+		hosts = append(hosts, HostData{Name: collector.Host(), Connected: collector.Connected()})
 	}
+	return data, hosts
+}
+
+func sendContainerDataToApp() {
+	app.ContainerData(collectContainerData())
+	app.AlertData(alertEngine.Alerts())
+}
 
-	app.ContainerData(data)
+// evaluateAlertsPeriodically evaluates the alert rules against the data of all containers until done is closed
+func evaluateAlertsPeriodically(done <-chan struct{}) {
+	for {
+		select {
+		case <-time.After(tunables().RefreshInterval):
+			data, _ := collectContainerData()
+			alertEngine.Evaluate(data, currentTime())
+		case <-done:
+			return
+		}
+	}
 }
 
 func main() {
+	var endpoints endpointsFlag
+	backendName := flag.String("backend", "auto", "container runtime to connect to: auto, docker or podman")
+	flag.Var(&endpoints, "host", "container runtime endpoint \"[name=]url[?backend=podman&tlscertpath=dir]\" with url unix://, tcp:// or ssh://, may be repeated to follow several hosts")
+	httpAddr := flag.String("http", "", "serve container snapshot as JSON and Prometheus metrics on given address, e.g. localhost:8080")
+	headless := flag.Bool("headless", false, "don't open a window, only serve the container snapshot given by -http")
+	historyDir := flag.String("history-dir", defaultHistoryDir(), "directory to persist metric history in, empty to keep history in memory only")
+	historyRetention := flag.Duration("history-retention", 24*time.Hour, "duration to keep persisted metric history")
+	configPath := flag.String("config", defaultConfigPath(), "YAML config file of tunables, reloaded when modified")
+	registerConfigFlags()
+	shellCommand := flag.String("exec-command", strings.Join(execCommand, " "), "command executed in a container by \"Open shell\"")
+	exportHistory := flag.String("export-history", "", "export the metric history of given container name, compose project or id prefix of at least 4 characters to the file given by -export-file and exit")
+	exportFile := flag.String("export-file", "history.csv", "file the history is exported to by -export-history, format is JSON lines for extension .jsonl, CSV otherwise")
+	recordPath := flag.String("record", "", "record stats, events and inspect results of all containers to given file, e.g. session.rec.gz")
+	replayPath := flag.String("replay", "", "replay a file recorded by -record instead of following container runtimes")
+	flag.Parse()
+
+	execCommand = strings.Fields(*shellCommand)
+	if len(execCommand) == 0 {
+		panic(fmt.Errorf("Empty -exec-command"))
+	}
+
+	if len(*recordPath) > 0 && len(*replayPath) > 0 {
+		panic(fmt.Errorf("Unable to record while replaying, use either -record or -replay"))
+	}
+	var recording *Recording
+	if len(*replayPath) > 0 {
+		loaded, err := LoadRecording(*replayPath)
+		if err != nil {
+			panic(fmt.Errorf("Unable to load recording: %v", err))
+		}
+		recording = loaded
+		replaying = true
+		*historyDir = ""
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		panic(fmt.Errorf("Unable to load config: %v", err))
+	}
+	config.Apply()
+
 	buildInfo := fmt.Sprintf("v%s\nbuilt %s\ncommit sha1 %s", versionTag, buildDate, versionSha1)
 	fmt.Println(buildInfo)
 
-	if err := clipboard.Init(); err != nil {
-		panic(fmt.Errorf("Unable to use clipboard: %v", err))
+	if *headless && len(*httpAddr) == 0 {
+		panic(fmt.Errorf("Running headless requires -http"))
+	}
+
+	if !*headless {
+		if err := clipboard.Init(); err != nil {
+			panic(fmt.Errorf("Unable to use clipboard: %v", err))
+		}
+	}
+
+	if len(*historyDir) > 0 {
+		store, err := OpenHistoryStore(*historyDir, *historyRetention)
+		if err != nil {
+			panic(fmt.Errorf("Unable to open history store: %v", err))
+		}
+		historyStore = store
+		defer historyStore.Close()
+	}
+
+	if len(*recordPath) > 0 {
+		recorder, err = NewRecorder(*recordPath)
+		if err != nil {
+			panic(fmt.Errorf("Unable to create recording: %v", err))
+		}
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				fmt.Printf("Failed to close recording: %v\n", err)
+			}
+		}()
+	}
+
+	var replayClock *ReplayClock
+	if recording != nil {
+		replayClock = NewReplayClock(recording)
+		for _, host := range recording.Hosts() {
+			collectors = append(collectors, NewCollector(host, replayBackendFactory(recording, host, replayClock)))
+		}
+		// followed containers are dropped and the replay starts over at the new position
+		replayClock.OnSeek(func() {
+			collectorMutex.RLock()
+			defer collectorMutex.RUnlock()
+			for _, collector := range collectors {
+				collector.SwitchBackend(collector.Host(), replayBackendFactory(recording, collector.Host(), replayClock))
+			}
+		})
+		currentTime = replayClock.Now
+		fmt.Printf("Replaying %s recorded from %s until %s\n", *replayPath,
+			recording.Start.Local().Format(time.DateTime), recording.End.Local().Format(time.DateTime))
+	} else if len(endpoints) == 0 {
+		_, dockerHost := os.LookupEnv("DOCKER_HOST")
+		dockerContext := currentDockerContext()
+		if *backendName != "podman" && !dockerHost && dockerContext != DefaultDockerContext {
+			context, err := findDockerContext(dockerContext)
+			if err != nil {
+				panic(err)
+			}
+			endpoints = append(endpoints, context.Endpoint())
+			daemonSelected = context.Name
+		} else {
+			newBackend, daemonName, err := backendFactoryByName(*backendName)
+			if err != nil {
+				panic(err)
+			}
+			collectors = append(collectors, NewCollector("local", recordingBackendFactory("local", newBackend)))
+			daemonSelected = daemonName
+		}
+	}
+	for _, endpoint := range endpoints {
+		collectors = append(collectors, NewCollector(endpoint.Name, recordingBackendFactory(endpoint.Name, endpoint.BackendFactory())))
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	getDockerStatsWithRetry(ctx)
+	if historyStore != nil {
+		go historyStore.CompactPeriodically(ctx.Done())
+	}
+	if len(*configPath) > 0 {
+		go WatchConfig(*configPath, ctx.Done())
+	}
+	notifyAlert := func(alert Alert) {
+		notify(alertNotification(alert))
+	}
+	alertEngine.OnFired(notifyAlert)
+	alertEngine.OnResolved(notifyAlert)
+	go evaluateAlertsPeriodically(ctx.Done())
+	for _, collector := range collectors {
+		collector.getDockerStatsWithRetry(ctx)
+	}
+
+	if len(*exportHistory) > 0 {
+		err := exportHistoryFromCli(*exportHistory, *exportFile, 10*time.Second)
+		cancel()
+		if err != nil {
+			panic(fmt.Errorf("Unable to export history: %v", err))
+		}
+		return
+	}
+
+	if len(*httpAddr) > 0 {
+		serveApi(ctx, *httpAddr)
+	}
+
+	if *headless {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		cancel()
+		return
+	}
 
 	app = NewApp()
 	app.BuildInfo(buildInfo)
+	app.OnStartContainer(startContainer)
 	app.OnStopContainer(stopContainer)
 	app.OnRestartContainer(restartContainer)
+	app.OnPauseContainer(pauseContainer)
+	app.OnUnpauseContainer(unpauseContainer)
+	app.OnKillContainer(killContainer)
+	app.OnFollowLogs(followLogs)
+	app.OnExecContainer(execContainer)
+	app.OnListProcesses(listProcesses)
+	app.OnFetchInspect(fetchInspect)
+	app.OnSignalProcess(signalProcess)
+	app.OnAcknowledgeAlert(alertEngine.Acknowledge)
+	app.OnSelectDaemon(selectDaemon)
+	app.OnReloadDaemons(reloadDaemons)
+
+	if replayClock != nil {
+		app.Replay(replayClock)
+	} else {
+		reloadDaemons()
+	}
 
 	go func() {
 		for {
 			select {
-			case <-time.After(1 * time.Second):
+			case <-time.After(tunables().RefreshInterval):
 				sendContainerDataToApp()
 			case <-ctx.Done():
 				return
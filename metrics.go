@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// prometheusMetric describes a metric exported in the Prometheus text exposition format
+type prometheusMetric struct {
+	name  string
+	help  string
+	kind  string
+	value func(data ContainerData) float64
+}
+
+var prometheusMetrics = []prometheusMetric{
+	{"container_hud_cpu_percent", "CPU usage in percent, a fully used cpu core counts as 100.", "gauge",
+		func(data ContainerData) float64 { return data.CpuPercent }},
+	{"container_hud_cpu_throttled_percent", "Percentage of cpu periods the container got throttled.", "gauge",
+		func(data ContainerData) float64 { return data.CpuThrottledPercent }},
+	{"container_hud_memory_bytes", "Memory usage in bytes.", "gauge",
+		func(data ContainerData) float64 { return float64(data.Memory) }},
+	{"container_hud_memory_limit_bytes", "Memory limit in bytes.", "gauge",
+		func(data ContainerData) float64 { return float64(data.MemoryLimit) }},
+	{"container_hud_network_receive_bytes_total", "Bytes received over network.", "counter",
+		func(data ContainerData) float64 { return float64(data.NetworkRx) }},
+	{"container_hud_network_transmit_bytes_total", "Bytes transmitted over network.", "counter",
+		func(data ContainerData) float64 { return float64(data.NetworkTx) }},
+	{"container_hud_block_read_bytes_total", "Bytes read from block devices.", "counter",
+		func(data ContainerData) float64 { return float64(data.BlockRead) }},
+	{"container_hud_block_write_bytes_total", "Bytes written to block devices.", "counter",
+		func(data ContainerData) float64 { return float64(data.BlockWrite) }},
+	{"container_hud_pids", "Number of processes.", "gauge",
+		func(data ContainerData) float64 { return float64(data.PIDs) }},
+}
+
+var prometheusHealthStates = []HealthState{UnknownHealth, Healthy, Unhealthy, StartingHealth}
+
+// prometheusLabelValue escapes given label value according to the text exposition format
+func prometheusLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// prometheusLabels returns the labels identifying given container
+func prometheusLabels(data ContainerData) string {
+	labels := [][2]string{
+		{"host", data.Host},
+		{"id", data.ID},
+		{"name", data.Name},
+		{"alternative_name", data.AlternativeName},
+		{"image", data.Image},
+		{"compose_project", data.DockerComposeProject},
+		{"compose_service", data.DockerComposeService},
+	}
+	var b strings.Builder
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, label[0], prometheusLabelValue(label[1]))
+	}
+	return b.String()
+}
+
+// writePrometheusMetrics writes the metrics of given containers in the Prometheus text exposition format
+func writePrometheusMetrics(w io.Writer, data []ContainerData) {
+	sort.SliceStable(data, func(i int, j int) bool {
+		if data[i].Host != data[j].Host {
+			return data[i].Host < data[j].Host
+		}
+		return data[i].ID < data[j].ID
+	})
+	labels := make([]string, len(data))
+	for i := range data {
+		labels[i] = prometheusLabels(data[i])
+	}
+
+	for _, metric := range prometheusMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		for i := range data {
+			fmt.Fprintf(w, "%s{%s} %g\n", metric.name, labels[i], metric.value(data[i]))
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP container_hud_health_status Health status of the container, 1 for the current status.")
+	fmt.Fprintln(w, "# TYPE container_hud_health_status gauge")
+	for i := range data {
+		for _, state := range prometheusHealthStates {
+			value := 0
+			if data[i].HealthStatus == state {
+				value = 1
+			}
+			fmt.Fprintf(w, "container_hud_health_status{%s,status=\"%s\"} %d\n", labels[i], state, value)
+		}
+	}
+}
+
+func handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	data, _ := collectContainerData()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheusMetrics(w, data)
+}
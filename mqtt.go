@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MQTTConfig configures publishing container state to a MQTT broker, publishing is disabled without broker
+type MQTTConfig struct {
+	// Broker is the URL of the broker, e.g. tcp://localhost:1883, ssl://broker:8883 or ws://broker:80/mqtt
+	Broker   string `yaml:"broker"`
+	ClientID string `yaml:"client_id"`
+	Username string `yaml:"username"`
+	// Password may reference environment variables, e.g. ${MQTT_PASSWORD}
+	Password string `yaml:"password"`
+	// Prefix is the first level of all topics
+	Prefix string `yaml:"prefix"`
+	// PublishInterval is the interval the state of containers is published in, only changed values are published
+	PublishInterval time.Duration `yaml:"publish_interval"`
+}
+
+// defaultMQTTConfig holds the values of keys missing in the MQTT config
+var defaultMQTTConfig = MQTTConfig{
+	ClientID:        "container-hud",
+	Prefix:          "container-hud",
+	PublishInterval: 5 * time.Second,
+}
+
+func (c *MQTTConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain MQTTConfig
+	config := plain(defaultMQTTConfig)
+	if err := node.Decode(&config); err != nil {
+		return err
+	}
+	*c = MQTTConfig(config)
+	return nil
+}
+
+// Validate returns an error describing the first invalid value
+func (c MQTTConfig) Validate() error {
+	if len(c.Broker) == 0 {
+		return nil
+	}
+	u, err := url.Parse(c.Broker)
+	if err != nil || !slices.Contains([]string{"tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss"}, u.Scheme) {
+		return fmt.Errorf("broker must be an URL like tcp://host:1883, got %q", c.Broker)
+	}
+	if len(c.Prefix) == 0 || strings.ContainsAny(c.Prefix, "+#") {
+		return fmt.Errorf("prefix must not be empty or contain wildcards, got %q", c.Prefix)
+	}
+	if c.PublishInterval < time.Second {
+		return fmt.Errorf("publish_interval must be at least 1s, got %s", c.PublishInterval)
+	}
+	return nil
+}
+
+// mqttEventKinds are the kinds of notifications published to the event topic of a container
+var mqttEventKinds = []string{NotifyStart, NotifyStop, NotifyDie, NotifyOOM}
+
+// MQTTPublisher publishes retained state topics and event topics of containers:
+//
+//	<prefix>/status                    "online" or "offline"
+//	<prefix>/<host>/<container>/state  e.g. "running"
+//	<prefix>/<host>/<container>/health e.g. "healthy"
+//	<prefix>/<host>/<container>/cpu    cpu usage in percent
+//	<prefix>/<host>/<container>/mem    memory usage in bytes
+//	<prefix>/<host>/<container>/event  notification as JSON on start, stop, die and oom
+//
+// The container level is the name of the container at the runtime, e.g. "shop-db-1", as it is unique per host
+// unlike the name of the compose service shown in the HUD.
+// It subscribes to <prefix>/<host>/<container>/command to stop or restart a container by payload "stop" or "restart".
+type MQTTPublisher struct {
+	config MQTTConfig
+	client mqtt.Client
+	done   chan struct{}
+
+	published      map[string]string // payloads of retained topics published
+	publishedMutex sync.Mutex
+}
+
+var _ NotificationChannel = &MQTTPublisher{}
+
+var (
+	mqttPublisher      *MQTTPublisher = nil
+	mqttPublisherMutex                = sync.Mutex{}
+)
+
+// setMQTTConfig (re)starts publishing to the broker of the config, the running publisher is kept if the config didn't change
+func setMQTTConfig(config MQTTConfig) {
+	mqttPublisherMutex.Lock()
+	defer mqttPublisherMutex.Unlock()
+	if mqttPublisher != nil && mqttPublisher.config == config {
+		return
+	}
+	mqttPublisher = nil
+	var channels []NotificationChannel
+	if len(config.Broker) > 0 {
+		mqttPublisher = NewMQTTPublisher(config)
+		channels = append(channels, mqttPublisher)
+	}
+	setNotificationChannels("mqtt", channels)
+}
+
+// NewMQTTPublisher connects to the broker and starts publishing, the connection is retried until Close is called
+func NewMQTTPublisher(config MQTTConfig) *MQTTPublisher {
+	p := &MQTTPublisher{
+		config:    config,
+		done:      make(chan struct{}),
+		published: make(map[string]string),
+	}
+	options := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(os.ExpandEnv(config.Password)).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(tunables().RetryInterval).
+		SetMaxReconnectInterval(time.Minute).
+		SetWill(p.topic("status"), "offline", 1, true).
+		SetOnConnectHandler(p.onConnect).
+		SetConnectionLostHandler(func(client mqtt.Client, err error) {
+			fmt.Printf("Lost connection to MQTT broker %s: %v\n", config.Broker, err)
+		})
+	p.client = mqtt.NewClient(options)
+	fmt.Printf("Connecting to MQTT broker %s...\n", config.Broker)
+	p.client.Connect()
+	go p.publishPeriodically()
+	return p
+}
+
+func (p *MQTTPublisher) Notify(notification Notification) {
+	if !slices.Contains(mqttEventKinds, notification.Kind) {
+		return
+	}
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+	p.client.Publish(p.topic(notification.Host, notification.RuntimeName, "event"), 1, false, payload)
+}
+
+func (p *MQTTPublisher) Close() {
+	close(p.done)
+	if p.client.IsConnectionOpen() {
+		p.client.Publish(p.topic("status"), 1, true, "offline").WaitTimeout(time.Second)
+	}
+	p.client.Disconnect(250)
+}
+
+// topic joins the levels to a topic below the prefix, characters not allowed in a level are replaced
+func (p *MQTTPublisher) topic(levels ...string) string {
+	replacer := strings.NewReplacer("/", "_", "+", "_", "#", "_")
+	topic := p.config.Prefix
+	for _, level := range levels {
+		topic += "/" + replacer.Replace(level)
+	}
+	return topic
+}
+
+// onConnect announces the publisher, subscribes to commands and republishes the state of all containers
+func (p *MQTTPublisher) onConnect(client mqtt.Client) {
+	fmt.Printf("Connected to MQTT broker %s\n", p.config.Broker)
+	client.Publish(p.topic("status"), 1, true, "online")
+	client.Subscribe(p.config.Prefix+"/+/+/command", 1, p.onCommand)
+	p.publishedMutex.Lock()
+	clear(p.published)
+	p.publishedMutex.Unlock()
+	go p.publishState()
+}
+
+// onCommand stops or restarts the container of the command topic
+func (p *MQTTPublisher) onCommand(client mqtt.Client, message mqtt.Message) {
+	command := strings.ToLower(strings.TrimSpace(string(message.Payload())))
+	data, _ := collectContainerData()
+	container, ok := p.commandContainer(message.Topic(), data)
+	if !ok {
+		fmt.Printf("Ignored MQTT command for unknown container of topic %s\n", message.Topic())
+		return
+	}
+	fmt.Printf("MQTT command %q for container %s (%s)\n", command, container.Name, container.ID)
+	switch command {
+	case "stop":
+		go stopContainer(container.ID)
+	case "restart":
+		go restartContainer(container.ID)
+	default:
+		fmt.Printf("Unknown MQTT command %q, expected \"stop\" or \"restart\"\n", command)
+	}
+}
+
+// commandContainer returns the container of the command topic
+func (p *MQTTPublisher) commandContainer(topic string, data []ContainerData) (ContainerData, bool) {
+	for _, container := range data {
+		if topic == p.topic(container.Host, container.Name, "command") {
+			return container, true
+		}
+	}
+	return ContainerData{}, false
+}
+
+func (p *MQTTPublisher) publishPeriodically() {
+	for {
+		select {
+		case <-time.After(p.config.PublishInterval):
+			p.publishState()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// stateTopics returns the payloads of the retained state topics of the containers
+func (p *MQTTPublisher) stateTopics(data []ContainerData) map[string]string {
+	topics := make(map[string]string)
+	for _, container := range data {
+		topics[p.topic(container.Host, container.Name, "state")] = container.State.String()
+		topics[p.topic(container.Host, container.Name, "health")] = container.HealthStatus.String()
+		topics[p.topic(container.Host, container.Name, "cpu")] = fmt.Sprintf("%.1f", container.CpuPercent)
+		topics[p.topic(container.Host, container.Name, "mem")] = fmt.Sprintf("%d", container.Memory)
+	}
+	return topics
+}
+
+// publishState publishes the changed state of all containers as retained topics,
+// the topics of removed containers are cleared
+func (p *MQTTPublisher) publishState() {
+	if !p.client.IsConnectionOpen() {
+		return
+	}
+	p.publishedMutex.Lock()
+	defer p.publishedMutex.Unlock()
+
+	data, _ := collectContainerData()
+	current := p.stateTopics(data)
+	for topic, payload := range current {
+		if published, ok := p.published[topic]; !ok || published != payload {
+			p.client.Publish(topic, 0, true, payload)
+			p.published[topic] = payload
+		}
+	}
+	for topic := range p.published {
+		if _, ok := current[topic]; !ok {
+			p.client.Publish(topic, 0, true, []byte{})
+			delete(p.published, topic)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMQTTTopicsOfSameServiceInDifferentProjects(t *testing.T) {
+	p := &MQTTPublisher{config: MQTTConfig{Prefix: "hud"}}
+	data := []ContainerData{
+		{ID: "c1", Host: "local", Name: "shop-db-1", AlternativeName: "db-1", DockerComposeProject: "shop", State: ContainerRunning},
+		{ID: "c2", Host: "local", Name: "blog-db-1", AlternativeName: "db-1", DockerComposeProject: "blog", State: ContainerExited},
+	}
+
+	topics := p.stateTopics(data)
+	if topics["hud/local/shop-db-1/state"] != "running" || topics["hud/local/blog-db-1/state"] != "exited" {
+		t.Errorf("expected separate state topics of the containers, got %v", topics)
+	}
+
+	if container, ok := p.commandContainer("hud/local/blog-db-1/command", data); !ok || container.ID != "c2" {
+		t.Errorf("expected command for container c2, got %v %v", container.ID, ok)
+	}
+	if _, ok := p.commandContainer("hud/local/db-1/command", data); ok {
+		t.Errorf("expected no container for the command topic of the ambiguous service name")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// kinds of notifications
+const (
+	NotifyStart    = "start"
+	NotifyStop     = "stop"
+	NotifyDie      = "die"
+	NotifyOOM      = "oom"
+	NotifyHealth   = "health"
+	NotifyAlert    = "alert"
+	NotifyResolved = "resolved"
+)
+
+// NotificationKinds are all kinds of notifications
+var NotificationKinds = []string{NotifyStart, NotifyStop, NotifyDie, NotifyOOM, NotifyHealth, NotifyAlert, NotifyResolved}
+
+// Notification is a container lifecycle event, a health change or an alert sent to the notification channels
+type Notification struct {
+	Kind            string    `json:"kind"`
+	Time            time.Time `json:"time"`
+	Host            string    `json:"host"`
+	ContainerID     string    `json:"containerId"`
+	ContainerName   string    `json:"containerName"`
+	RuntimeName     string    `json:"runtimeName,omitempty"` // name of the container at the runtime, unique per host
+	Image           string    `json:"image,omitempty"`
+	ComposeProject  string    `json:"composeProject,omitempty"`
+	Message         string    `json:"message"`
+	HealthStatus    string    `json:"healthStatus,omitempty"`
+	ExitCode        int       `json:"exitCode,omitempty"`
+	Rule            string    `json:"rule,omitempty"`
+	AlertID         int       `json:"alertId,omitempty"`
+	AlertFiredAt    int64     `json:"alertFiredAt,omitempty"`
+	AlertResolvedAt int64     `json:"alertResolvedAt,omitempty"`
+}
+
+// NotificationChannel delivers notifications, e.g. to a webhook
+type NotificationChannel interface {
+	// Notify queues the notification for delivery, it must not block
+	Notify(notification Notification)
+	// Close stops delivering notifications
+	Close()
+}
+
+var (
+	notificationChannels      = make(map[string][]NotificationChannel) // channels by kind of channel, e.g. "webhook"
+	notificationChannelsMutex = sync.Mutex{}
+)
+
+// setNotificationChannels replaces the channels of given kind, replaced channels not contained in channels are closed
+func setNotificationChannels(kind string, channels []NotificationChannel) {
+	notificationChannelsMutex.Lock()
+	defer notificationChannelsMutex.Unlock()
+	for _, channel := range notificationChannels[kind] {
+		if !slices.Contains(channels, channel) {
+			channel.Close()
+		}
+	}
+	notificationChannels[kind] = channels
+}
+
+var (
+	desktopNotifier      *DesktopNotifier = nil
+	desktopNotifierMutex                  = sync.Mutex{}
+)
+
+// setDesktopNotifications enables or disables desktop notifications, an enabled notifier is kept
+func setDesktopNotifications(enabled bool) {
+	desktopNotifierMutex.Lock()
+	defer desktopNotifierMutex.Unlock()
+	if enabled == (desktopNotifier != nil) {
+		return
+	}
+	desktopNotifier = nil
+	var channels []NotificationChannel
+	if enabled {
+		notifier, err := NewDesktopNotifier()
+		if err != nil {
+			fmt.Printf("Desktop notifications unavailable: %v\n", err)
+		} else {
+			desktopNotifier = notifier
+			channels = append(channels, notifier)
+		}
+	}
+	setNotificationChannels("desktop", channels)
+}
+
+// addNotificationChannel adds a channel of given kind
+func addNotificationChannel(kind string, channel NotificationChannel) {
+	notificationChannelsMutex.Lock()
+	defer notificationChannelsMutex.Unlock()
+	notificationChannels[kind] = append(notificationChannels[kind], channel)
+}
+
+// removeNotificationChannel removes a channel of given kind, the channel is closed
+func removeNotificationChannel(kind string, channel NotificationChannel) {
+	notificationChannelsMutex.Lock()
+	defer notificationChannelsMutex.Unlock()
+	notificationChannels[kind] = slices.DeleteFunc(slices.Clone(notificationChannels[kind]), func(c NotificationChannel) bool {
+		return c == channel
+	})
+	channel.Close()
+}
+
+// notify sends the notification to all channels
+func notify(notification Notification) {
+	if replaying {
+		return
+	}
+	if notification.Time.IsZero() {
+		notification.Time = time.Now()
+	}
+	notificationChannelsMutex.Lock()
+	defer notificationChannelsMutex.Unlock()
+	for _, channels := range notificationChannels {
+		for _, channel := range channels {
+			channel.Notify(notification)
+		}
+	}
+}
+
+// containerNotification creates a notification of given kind about the container
+func containerNotification(kind string, data ContainerData, message string) Notification {
+	return Notification{
+		Kind:           kind,
+		Time:           time.Now(),
+		Host:           data.Host,
+		ContainerID:    data.ID,
+		ContainerName:  data.AlternativeName,
+		RuntimeName:    data.Name,
+		Image:          data.Image,
+		ComposeProject: data.DockerComposeProject,
+		Message:        message,
+		ExitCode:       data.ExitCode,
+	}
+}
+
+// alertNotification creates a notification about a fired or resolved alert
+func alertNotification(alert Alert) Notification {
+	kind, message := NotifyAlert, fmt.Sprintf("Alert %q fired: %s", alert.Rule, alert.Message)
+	if !alert.IsFiring() {
+		kind, message = NotifyResolved, fmt.Sprintf("Alert %q resolved", alert.Rule)
+	}
+	notification := Notification{
+		Kind:          kind,
+		Time:          time.Now(),
+		Host:          alert.Host,
+		ContainerID:   alert.ContainerID,
+		ContainerName: alert.ContainerName,
+		Message:       fmt.Sprintf("%s: %s", alert.ContainerName, message),
+		Rule:          alert.Rule,
+		AlertID:       alert.ID,
+		AlertFiredAt:  alert.FiredAt.Unix(),
+	}
+	if !alert.IsFiring() {
+		notification.AlertResolvedAt = alert.ResolvedAt.Unix()
+	}
+	return notification
+}
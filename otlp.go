@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// OTLPConfig configures pushing container metrics to an OpenTelemetry collector, exporting is disabled without endpoint
+type OTLPConfig struct {
+	// Endpoint is the URL of the collector, e.g. http://localhost:4318 for protocol http or http://localhost:4317 for grpc
+	Endpoint string `yaml:"endpoint"`
+	// Protocol is "http" or "grpc"
+	Protocol string `yaml:"protocol"`
+	// Headers are sent with every export, values may reference environment variables
+	Headers map[string]string `yaml:"headers"`
+	// Interval is the interval metrics are exported in
+	Interval time.Duration `yaml:"interval"`
+}
+
+// defaultOTLPConfig holds the values of keys missing in the OTLP config
+var defaultOTLPConfig = OTLPConfig{
+	Protocol: "http",
+	Interval: 15 * time.Second,
+}
+
+func (c *OTLPConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain OTLPConfig
+	config := plain(defaultOTLPConfig)
+	if err := node.Decode(&config); err != nil {
+		return err
+	}
+	*c = OTLPConfig(config)
+	return nil
+}
+
+// Validate returns an error describing the first invalid value
+func (c OTLPConfig) Validate() error {
+	if len(c.Endpoint) == 0 {
+		return nil
+	}
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("endpoint must be an absolute http or https URL, got %q", c.Endpoint)
+	}
+	if c.Protocol != "http" && c.Protocol != "grpc" {
+		return fmt.Errorf("protocol must be \"http\" or \"grpc\", got %q", c.Protocol)
+	}
+	if c.Interval < time.Second {
+		return fmt.Errorf("interval must be at least 1s, got %s", c.Interval)
+	}
+	return nil
+}
+
+// otlpMetric describes a metric exported via OTLP
+type otlpMetric struct {
+	name        string
+	description string
+	unit        string
+	counter     bool
+	value       func(data ContainerData) float64
+}
+
+var otlpMetrics = []otlpMetric{
+	{"container_hud.cpu.usage", "CPU usage in percent, a fully used cpu core counts as 100.", "%", false,
+		func(data ContainerData) float64 { return data.CpuPercent }},
+	{"container_hud.cpu.throttled", "Percentage of cpu periods the container got throttled.", "%", false,
+		func(data ContainerData) float64 { return data.CpuThrottledPercent }},
+	{"container_hud.memory.usage", "Memory usage in bytes.", "By", false,
+		func(data ContainerData) float64 { return float64(data.Memory) }},
+	{"container_hud.memory.limit", "Memory limit in bytes.", "By", false,
+		func(data ContainerData) float64 { return float64(data.MemoryLimit) }},
+	{"container_hud.network.receive", "Bytes received over network.", "By", true,
+		func(data ContainerData) float64 { return float64(data.NetworkRx) }},
+	{"container_hud.network.transmit", "Bytes transmitted over network.", "By", true,
+		func(data ContainerData) float64 { return float64(data.NetworkTx) }},
+	{"container_hud.block.read", "Bytes read from block devices.", "By", true,
+		func(data ContainerData) float64 { return float64(data.BlockRead) }},
+	{"container_hud.block.write", "Bytes written to block devices.", "By", true,
+		func(data ContainerData) float64 { return float64(data.BlockWrite) }},
+	{"container_hud.pids", "Number of processes.", "{process}", false,
+		func(data ContainerData) float64 { return float64(data.PIDs) }},
+}
+
+// otlpResource returns the resource describing given container, the service is taken from the compose labels
+func otlpResource(data ContainerData) *resource.Resource {
+	attributes := []attribute.KeyValue{
+		attribute.String("container.id", data.ID),
+		attribute.String("container.name", data.Name),
+		attribute.String("container.image.name", data.Image),
+		attribute.String("host.name", data.Host),
+	}
+	if len(data.DockerComposeProject) == 0 {
+		attributes = append(attributes, attribute.String("service.name", data.AlternativeName))
+	} else {
+		attributes = append(attributes,
+			attribute.String("service.name", data.DockerComposeService),
+			attribute.String("service.namespace", data.DockerComposeProject),
+			attribute.String("service.instance.id", data.ID),
+			attribute.String("docker.compose.project", data.DockerComposeProject),
+			attribute.String("docker.compose.project.dir", data.DockerComposeProjectDir),
+			attribute.String("docker.compose.service", data.DockerComposeService),
+			attribute.Int("docker.compose.container_number", data.DockerComposeContainerNumber),
+		)
+	}
+	return resource.NewSchemaless(attributes...)
+}
+
+// otlpResourceMetrics returns the metrics of given container
+func otlpResourceMetrics(data ContainerData, now time.Time) *metricdata.ResourceMetrics {
+	start := time.Unix(data.Created, 0)
+	metrics := make([]metricdata.Metrics, 0, len(otlpMetrics)+1)
+	for _, m := range otlpMetrics {
+		point := []metricdata.DataPoint[float64]{{StartTime: start, Time: now, Value: m.value(data)}}
+		var aggregation metricdata.Aggregation = metricdata.Gauge[float64]{DataPoints: point}
+		if m.counter {
+			aggregation = metricdata.Sum[float64]{
+				DataPoints:  point,
+				Temporality: metricdata.CumulativeTemporality,
+				IsMonotonic: true,
+			}
+		}
+		metrics = append(metrics, metricdata.Metrics{Name: m.name, Description: m.description, Unit: m.unit, Data: aggregation})
+	}
+
+	health := make([]metricdata.DataPoint[int64], 0, len(prometheusHealthStates))
+	for _, state := range prometheusHealthStates {
+		value := int64(0)
+		if data.HealthStatus == state {
+			value = 1
+		}
+		health = append(health, metricdata.DataPoint[int64]{
+			Attributes: attribute.NewSet(attribute.String("status", state.String())),
+			StartTime:  start,
+			Time:       now,
+			Value:      value,
+		})
+	}
+	metrics = append(metrics, metricdata.Metrics{
+		Name:        "container_hud.health.status",
+		Description: "Health status of the container, 1 for the current status.",
+		Unit:        "1",
+		Data:        metricdata.Gauge[int64]{DataPoints: health},
+	})
+
+	return &metricdata.ResourceMetrics{
+		Resource: otlpResource(data),
+		ScopeMetrics: []metricdata.ScopeMetrics{
+			{Scope: instrumentation.Scope{Name: "github.com/manuel-koch/go-docker-hud"}, Metrics: metrics},
+		},
+	}
+}
+
+// OTLPExporter periodically pushes the metrics of all running containers to an OpenTelemetry collector
+type OTLPExporter struct {
+	config   OTLPConfig
+	exporter metric.Exporter
+	done     chan struct{}
+}
+
+var (
+	otlpExporter      *OTLPExporter = nil
+	otlpExporterMutex               = sync.Mutex{}
+)
+
+// setOTLPConfig (re)starts exporting to the collector of the config, the running exporter is kept if the config didn't change
+func setOTLPConfig(config OTLPConfig) {
+	otlpExporterMutex.Lock()
+	defer otlpExporterMutex.Unlock()
+	if otlpExporter != nil && otlpExporter.config.Equal(config) {
+		return
+	}
+	if otlpExporter != nil {
+		otlpExporter.Close()
+		otlpExporter = nil
+	}
+	if len(config.Endpoint) == 0 {
+		return
+	}
+	exporter, err := NewOTLPExporter(config)
+	if err != nil {
+		fmt.Printf("Failed to export metrics to %s: %v\n", config.Endpoint, err)
+		return
+	}
+	otlpExporter = exporter
+}
+
+// Equal returns true if both configs have the same values
+func (c OTLPConfig) Equal(other OTLPConfig) bool {
+	if c.Endpoint != other.Endpoint || c.Protocol != other.Protocol || c.Interval != other.Interval || len(c.Headers) != len(other.Headers) {
+		return false
+	}
+	for name, value := range c.Headers {
+		if otherValue, ok := other.Headers[name]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// NewOTLPExporter creates the exporter of the protocol of the config and starts exporting
+func NewOTLPExporter(config OTLPConfig) (*OTLPExporter, error) {
+	headers := make(map[string]string, len(config.Headers))
+	for name, value := range config.Headers {
+		headers[name] = os.ExpandEnv(value)
+	}
+
+	var exporter metric.Exporter
+	var err error
+	ctx := context.Background()
+	switch config.Protocol {
+	case "grpc":
+		options := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpointURL(config.Endpoint), otlpmetricgrpc.WithHeaders(headers)}
+		exporter, err = otlpmetricgrpc.New(ctx, options...)
+	default:
+		options := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpointURL(config.Endpoint), otlpmetrichttp.WithHeaders(headers)}
+		exporter, err = otlpmetrichttp.New(ctx, options...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e := &OTLPExporter{
+		config:   config,
+		exporter: exporter,
+		done:     make(chan struct{}),
+	}
+	fmt.Printf("Exporting metrics via OTLP/%s to %s every %s\n", config.Protocol, config.Endpoint, config.Interval)
+	go e.exportPeriodically()
+	return e, nil
+}
+
+// Close stops exporting
+func (e *OTLPExporter) Close() {
+	close(e.done)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = e.exporter.Shutdown(ctx)
+}
+
+func (e *OTLPExporter) exportPeriodically() {
+	for {
+		select {
+		case <-time.After(e.config.Interval):
+			if err := e.export(); err != nil {
+				fmt.Printf("Failed to export metrics to %s: %v\n", e.config.Endpoint, err)
+			}
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// export sends the metrics of every running container, each container being its own resource
+func (e *OTLPExporter) export() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.Interval)
+	defer cancel()
+	data, _ := collectContainerData()
+	now := time.Now()
+	for _, container := range data {
+		if container.State != ContainerRunning || container.LastUpdated == 0 {
+			continue
+		}
+		if err := e.exporter.Export(ctx, otlpResourceMetrics(container, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	collector_metrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpReceiver is a stand-in OpenTelemetry collector recording the metrics exported to it
+type otlpReceiver struct {
+	collector_metrics.UnimplementedMetricsServiceServer
+
+	mutex    sync.Mutex
+	requests []*collector_metrics.ExportMetricsServiceRequest
+	paths    []string
+	tokens   []string // values of the authorization header of the requests
+}
+
+func (r *otlpReceiver) record(request *collector_metrics.ExportMetricsServiceRequest, path string, token string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests = append(r.requests, request)
+	r.paths = append(r.paths, path)
+	r.tokens = append(r.tokens, token)
+}
+
+// Export receives metrics via gRPC
+func (r *otlpReceiver) Export(ctx context.Context, request *collector_metrics.ExportMetricsServiceRequest) (*collector_metrics.ExportMetricsServiceResponse, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		token = md.Get("authorization")[0]
+	}
+	r.record(request, "", token)
+	return &collector_metrics.ExportMetricsServiceResponse{}, nil
+}
+
+// ServeHTTP receives metrics via HTTP as protobuf
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	request := &collector_metrics.ExportMetricsServiceRequest{}
+	if err == nil {
+		err = proto.Unmarshal(body, request)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.record(request, req.URL.Path, req.Header.Get("Authorization"))
+	response, _ := proto.Marshal(&collector_metrics.ExportMetricsServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(response)
+}
+
+// startOTLPHTTPReceiver listens for OTLP/http until the test ended, returns the endpoint
+func startOTLPHTTPReceiver(t *testing.T) (*otlpReceiver, string) {
+	receiver := &otlpReceiver{}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	return receiver, server.URL
+}
+
+// startOTLPGRPCReceiver listens for OTLP/grpc until the test ended, returns the endpoint
+func startOTLPGRPCReceiver(t *testing.T) (*otlpReceiver, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver := &otlpReceiver{}
+	server := grpc.NewServer()
+	collector_metrics.RegisterMetricsServiceServer(server, receiver)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return receiver, "http://" + listener.Addr().String()
+}
+
+func testOTLPExport(t *testing.T, protocol string, receiver *otlpReceiver, endpoint string, expectedPath string) {
+	t.Setenv("OTLP_TOKEN", "secret")
+	backend := newFakeBackend(fakeContainer{ID: "c1", Name: "shop-web-1", Status: "running", Labels: map[string]string{
+		"com.docker.compose.project": "shop",
+		"com.docker.compose.service": "web",
+	}})
+	collector := startGlobalCollector(t, backend)
+	backend.sendStats(t, "c1", 100, 1000, 2048)
+	waitFor(t, "stats of container", func() bool {
+		data, _ := containerData(collector, "c1")
+		return data.LastUpdated > 0
+	})
+
+	exporter, err := NewOTLPExporter(OTLPConfig{
+		Endpoint: endpoint,
+		Protocol: protocol,
+		Headers:  map[string]string{"Authorization": "Bearer ${OTLP_TOKEN}"},
+		Interval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.Close()
+	if err := exporter.export(); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+	if len(receiver.requests) != 1 {
+		t.Fatalf("expected 1 export, got %d", len(receiver.requests))
+	}
+	if receiver.paths[0] != expectedPath {
+		t.Errorf("expected export to %q, got %q", expectedPath, receiver.paths[0])
+	}
+	if receiver.tokens[0] != "Bearer secret" {
+		t.Errorf("expected expanded authorization header, got %q", receiver.tokens[0])
+	}
+	resourceMetrics := receiver.requests[0].GetResourceMetrics()
+	if len(resourceMetrics) != 1 {
+		t.Fatalf("expected metrics of 1 container, got %d", len(resourceMetrics))
+	}
+	attributes := make(map[string]string)
+	for _, attribute := range resourceMetrics[0].GetResource().GetAttributes() {
+		attributes[attribute.GetKey()] = attribute.GetValue().GetStringValue()
+	}
+	if attributes["container.id"] != "c1" || attributes["service.namespace"] != "shop" || attributes["service.name"] != "web" {
+		t.Errorf("unexpected resource attributes %v", attributes)
+	}
+	values := make(map[string]float64)
+	for _, scope := range resourceMetrics[0].GetScopeMetrics() {
+		for _, m := range scope.GetMetrics() {
+			for _, point := range append(m.GetGauge().GetDataPoints(), m.GetSum().GetDataPoints()...) {
+				values[m.GetName()] += point.GetAsDouble() + float64(point.GetAsInt())
+			}
+		}
+	}
+	if values["container_hud.memory.usage"] != 2048 || values["container_hud.health.status"] != 1 {
+		t.Errorf("unexpected metric values %v", values)
+	}
+}
+
+func TestOTLPExporterPushesMetricsViaHTTP(t *testing.T) {
+	receiver, endpoint := startOTLPHTTPReceiver(t)
+	testOTLPExport(t, "http", receiver, endpoint, "/v1/metrics")
+}
+
+func TestOTLPExporterPushesMetricsToCustomPathViaHTTP(t *testing.T) {
+	receiver, endpoint := startOTLPHTTPReceiver(t)
+	testOTLPExport(t, "http", receiver, endpoint+"/otlp/v1/metrics", "/otlp/v1/metrics")
+}
+
+func TestOTLPExporterPushesMetricsViaGRPC(t *testing.T) {
+	receiver, endpoint := startOTLPGRPCReceiver(t)
+	testOTLPExport(t, "grpc", receiver, endpoint, "")
+}
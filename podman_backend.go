@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	types_container "github.com/docker/docker/api/types/container"
+	types_event "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	"os"
+	"path/filepath"
+)
+
+var _ ContainerBackend = &PodmanBackend{}
+
+// PodmanBackend talks to Podman using its Docker compatible API
+type PodmanBackend struct {
+	*DockerBackend
+	socket string
+}
+
+// NewPodmanBackend creates a backend for the Podman service listening at given unix socket
+func NewPodmanBackend(socket string) (*PodmanBackend, error) {
+	docker, err := NewDockerBackend(client.WithHost("unix://" + socket))
+	if err != nil {
+		return nil, err
+	}
+	return &PodmanBackend{DockerBackend: docker, socket: socket}, nil
+}
+
+// podmanSocketCandidates returns the locations of rootless and rootful podman sockets
+func podmanSocketCandidates() []string {
+	var candidates []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); len(runtimeDir) > 0 {
+		candidates = append(candidates, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+	return candidates
+}
+
+// podmanDaemonName returns the name of the podman socket in the daemon menu
+func podmanDaemonName(socket string) string {
+	return "podman " + socket
+}
+
+// discoverPodmanSockets returns the podman sockets that exist on this host, rootless sockets first
+func discoverPodmanSockets() []string {
+	var sockets []string
+	for _, candidate := range podmanSocketCandidates() {
+		if stat, err := os.Stat(candidate); err == nil && stat.Mode()&os.ModeSocket != 0 {
+			sockets = append(sockets, candidate)
+		}
+	}
+	return sockets
+}
+
+func (b *PodmanBackend) Name() string {
+	return "podman"
+}
+
+// TopPIDScope returns the pids of the container, Podman executes ps in the pid namespace of the container
+func (b *PodmanBackend) TopPIDScope() PIDScope {
+	return ContainerPIDs
+}
+
+// ContainerInspect handles the "Healthcheck" field used by Podman instead of "Health"
+func (b *PodmanBackend) ContainerInspect(ctx context.Context, id string) (types_container.InspectResponse, error) {
+	inspect, raw, err := b.cli.ContainerInspectWithRaw(ctx, id, false)
+	if err != nil {
+		return inspect, err
+	}
+	if inspect.State != nil && inspect.State.Health == nil {
+		var podmanInspect struct {
+			State struct {
+				Healthcheck *types_container.Health
+			}
+		}
+		if err := json.Unmarshal(raw, &podmanInspect); err == nil && podmanInspect.State.Healthcheck != nil {
+			inspect.State.Health = podmanInspect.State.Healthcheck
+		}
+	}
+	return inspect, nil
+}
+
+// Events maps the Podman specific spelling of events to the one used by Docker
+func (b *PodmanBackend) Events(ctx context.Context) (<-chan types_event.Message, <-chan error) {
+	podmanEvents, errs := b.DockerBackend.Events(ctx)
+	events := make(chan types_event.Message)
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case event, ok := <-podmanEvents:
+				if !ok {
+					return
+				}
+				if len(event.Action) == 0 {
+					event.Action = types_event.Action(event.Status)
+				}
+				if len(event.Actor.ID) == 0 {
+					event.Actor.ID = event.ID
+				}
+				if event.Action == "died" {
+					event.Action = types_event.ActionDie
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, errs
+}
+
+func podmanBackendFactory(socket string) BackendFactory {
+	return func() (ContainerBackend, error) {
+		return NewPodmanBackend(socket)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/AllenDang/giu"
+	"github.com/inhies/go-bytesize"
+	"image/color"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// ProcessRefreshInterval is the interval the process list of a container is refreshed in
+	ProcessRefreshInterval = 2 * time.Second
+
+	ProcessTableHeight = 250
+)
+
+var (
+	ProcessNewColor      = color.RGBA{R: 40, G: 110, B: 40, A: 255}
+	ProcessVanishedColor = color.RGBA{R: 120, G: 40, B: 40, A: 255}
+)
+
+type ProcessSortMode int32
+
+const (
+	ProcessSortByPID ProcessSortMode = iota
+	ProcessSortByUser
+	ProcessSortByCPU
+	ProcessSortByRSS
+	ProcessSortByCommand
+)
+
+// processEntry is a process shown in the process list, new and vanished processes are highlighted until the next refresh
+type processEntry struct {
+	Process
+	New      bool
+	Vanished bool
+}
+
+// ProcessList periodically refreshes the processes of a container
+type ProcessList struct {
+	id string
+
+	mutex     sync.Mutex
+	processes []processEntry
+	err       string
+	updated   time.Time
+
+	sortMode       ProcessSortMode
+	sortDescending bool
+
+	cancel context.CancelFunc
+}
+
+// NewProcessList starts refreshing the processes of given container until Stop is called
+func NewProcessList(id string, list func(ctx context.Context, id string) ([]Process, error)) *ProcessList {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &ProcessList{
+		id:             id,
+		sortMode:       ProcessSortByCPU,
+		sortDescending: true,
+		cancel:         cancel,
+	}
+	go func() {
+		for {
+			processes, err := list(ctx, id)
+			if ctx.Err() != nil {
+				return
+			}
+			p.update(processes, err)
+			select {
+			case <-time.After(ProcessRefreshInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// ID returns the id of the container
+func (p *ProcessList) ID() string {
+	return p.id
+}
+
+// Stop stops refreshing the processes
+func (p *ProcessList) Stop() {
+	p.cancel()
+}
+
+// update replaces the processes, processes not known from the previous refresh are marked new,
+// processes of the previous refresh that are gone are kept and marked vanished
+func (p *ProcessList) update(processes []Process, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err != nil {
+		p.err = err.Error()
+		giu.Update()
+		return
+	}
+
+	previous := make(map[int]processEntry, len(p.processes))
+	listed := make(map[int]Process, len(p.processes))
+	for _, entry := range p.processes {
+		if !entry.Vanished {
+			previous[entry.PID] = entry
+			listed[entry.PID] = entry.Process
+		}
+	}
+	now := time.Now()
+	if !p.updated.IsZero() {
+		updateCPUPercent(processes, listed, now.Sub(p.updated))
+	}
+	entries := make([]processEntry, 0, len(processes))
+	for _, process := range processes {
+		_, known := previous[process.PID]
+		entries = append(entries, processEntry{Process: process, New: !known && !p.updated.IsZero()})
+		delete(previous, process.PID)
+	}
+	for _, entry := range previous {
+		entries = append(entries, processEntry{Process: entry.Process, Vanished: true})
+	}
+	p.processes = entries
+	p.err = ""
+	p.updated = now
+	p.sort()
+
+	giu.Update()
+}
+
+func (p *ProcessList) sort() {
+	sort.SliceStable(p.processes, func(i int, j int) bool {
+		a, b := p.processes[i], p.processes[j]
+		if p.sortDescending {
+			a, b = b, a
+		}
+		switch p.sortMode {
+		case ProcessSortByUser:
+			return a.User < b.User
+		case ProcessSortByCPU:
+			return a.CPUPercent < b.CPUPercent
+		case ProcessSortByRSS:
+			return a.RSS < b.RSS
+		case ProcessSortByCommand:
+			return a.Command < b.Command
+		default:
+			return a.PID < b.PID
+		}
+	})
+}
+
+// sortHeader returns a column header, a click sorts by the column or toggles the sort order
+func (p *ProcessList) sortHeader(name string, mode ProcessSortMode) giu.Widget {
+	if p.sortMode == mode {
+		if p.sortDescending {
+			name += " v"
+		} else {
+			name += " ^"
+		}
+	}
+	return giu.Selectable(name).OnClick(func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.sortMode == mode {
+			p.sortDescending = !p.sortDescending
+		} else {
+			p.sortMode = mode
+			p.sortDescending = mode == ProcessSortByCPU || mode == ProcessSortByRSS
+		}
+		p.sort()
+	})
+}
+
+// Render shows the processes as a table, signal is called to send a signal to a process
+func (p *ProcessList) Render(signal func(pid int, signal string)) giu.Widget {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var status string
+	switch {
+	case len(p.err) > 0:
+		status = fmt.Sprintf("Failed to list processes: %s", p.err)
+	case p.updated.IsZero():
+		status = "Listing processes..."
+	default:
+		status = fmt.Sprintf("%d processes, updated %s", len(p.processes), p.updated.Format("15:04:05"))
+	}
+
+	rows := []*giu.TableRowWidget{
+		giu.TableRow(
+			p.sortHeader("PID", ProcessSortByPID),
+			p.sortHeader("User", ProcessSortByUser),
+			p.sortHeader("CPU", ProcessSortByCPU),
+			p.sortHeader("RSS", ProcessSortByRSS),
+			p.sortHeader("Command", ProcessSortByCommand),
+			giu.Label(""),
+		).Flags(giu.TableRowFlagsHeaders),
+	}
+	for _, entry := range p.processes {
+		pid := entry.PID
+		row := giu.TableRow(
+			giu.Label(fmt.Sprintf("%d", pid)),
+			giu.Label(entry.User),
+			giu.Label(fmt.Sprintf("%0.1f %%", entry.CPUPercent)),
+			giu.Label(bytesize.New(float64(entry.RSS)).String()),
+			giu.Label(entry.Command),
+			giu.Condition(entry.Vanished,
+				giu.Layout{giu.Label("vanished")},
+				giu.Layout{
+					giu.Button(fmt.Sprintf("Signal##%d", pid)),
+					giu.ContextMenu().ID(fmt.Sprintf("signals%d", pid)).MouseButton(giu.MouseButtonLeft).Layout(
+						giu.Custom(func() {
+							for _, s := range KillSignals {
+								sig := s
+								giu.Selectable(sig).OnClick(func() {
+									go signal(pid, sig)
+								}).Build()
+							}
+						}),
+					),
+				},
+			),
+		)
+		if entry.New {
+			row.BgColor(ProcessNewColor)
+		} else if entry.Vanished {
+			row.BgColor(ProcessVanishedColor)
+		}
+		rows = append(rows, row)
+	}
+
+	return giu.Layout{
+		giu.Label(status),
+		giu.Table().ID("processes"+p.id).Freeze(0, 1).Size(-1, ProcessTableHeight).Rows(rows...),
+	}
+}
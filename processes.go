@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	types_container "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// topArguments are the ps arguments used to list the processes of a container,
+// the default columns of the runtime are used if the arguments are rejected
+var topArguments = []string{"aux"}
+
+// Process is a process running in a container
+type Process struct {
+	PID        int // as reported by the runtime, see PIDScope
+	User       string
+	CPUPercent float64
+	CPUTime    time.Duration // cpu time consumed since start of the process, -1 if unknown
+	RSS        uint64        // resident memory in bytes
+	Command    string
+}
+
+// listContainerProcesses returns the processes running in given container
+func listContainerProcesses(ctx context.Context, backend ContainerBackend, id string) ([]Process, error) {
+	top, err := backend.ContainerTop(ctx, id, topArguments)
+	if err != nil {
+		top, err = backend.ContainerTop(ctx, id, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return parseTopResponse(top), nil
+}
+
+// parseTopResponse picks the known columns of the ps output, missing columns are left empty
+func parseTopResponse(top types_container.TopResponse) []Process {
+	column := func(titles ...string) int {
+		for idx, title := range top.Titles {
+			for _, t := range titles {
+				if strings.EqualFold(title, t) {
+					return idx
+				}
+			}
+		}
+		return -1
+	}
+	pidIdx := column("PID")
+	userIdx := column("USER", "UID")
+	cpuIdx := column("%CPU", "C")
+	timeIdx := column("TIME")
+	rssIdx := column("RSS")
+	cmdIdx := column("COMMAND", "CMD", "ARGS")
+
+	processes := make([]Process, 0, len(top.Processes))
+	for _, values := range top.Processes {
+		value := func(idx int) string {
+			if idx < 0 || idx >= len(values) {
+				return ""
+			}
+			return values[idx]
+		}
+		pid, err := strconv.Atoi(value(pidIdx))
+		if err != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(value(cpuIdx), 64)
+		rss, _ := strconv.ParseUint(value(rssIdx), 10, 64)
+		processes = append(processes, Process{
+			PID:        pid,
+			User:       value(userIdx),
+			CPUPercent: cpu,
+			CPUTime:    parseCPUTime(value(timeIdx)),
+			RSS:        rss * 1024,
+			Command:    value(cmdIdx),
+		})
+	}
+	return processes
+}
+
+// parseCPUTime parses the TIME column of ps like "[[DD-]HH:]MM:SS" or a duration like "1m2s" used by Podman,
+// returns -1 if the time is invalid
+func parseCPUTime(value string) time.Duration {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration
+	}
+	var days int
+	if d, rest, ok := strings.Cut(value, "-"); ok {
+		var err error
+		if days, err = strconv.Atoi(d); err != nil {
+			return -1
+		}
+		value = rest
+	}
+	parts := strings.Split(value, ":")
+	if len(value) == 0 || len(parts) > 3 {
+		return -1
+	}
+	seconds := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return -1
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second
+}
+
+// updateCPUPercent replaces the cpu usage averaged over the lifetime of the processes reported by ps
+// with the cpu usage since the previous listing, computed from the change of the consumed cpu time
+func updateCPUPercent(processes []Process, previous map[int]Process, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	for i, process := range processes {
+		before, ok := previous[process.PID]
+		if !ok || before.CPUTime < 0 || process.CPUTime < before.CPUTime {
+			continue
+		}
+		processes[i].CPUPercent = float64(process.CPUTime-before.CPUTime) / float64(elapsed) * 100
+	}
+}
+
+// signalContainerProcess sends given signal to a process of the container by executing kill in the container,
+// pid is a pid listed by the backend
+func signalContainerProcess(ctx context.Context, backend ContainerBackend, id string, pid int, signal string) error {
+	switch backend.TopPIDScope() {
+	case LocalHostPIDs:
+		pid = namespacePID(pid)
+	case RemoteHostPIDs:
+		return fmt.Errorf("pid %d of the remote host can't be mapped to a pid in the container", pid)
+	}
+	execID, err := backend.ContainerExecCreate(ctx, id, types_container.ExecOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          []string{"kill", "-s", strings.TrimPrefix(signal, "SIG"), strconv.Itoa(pid)},
+	})
+	if err != nil {
+		return err
+	}
+	conn, err := backend.ContainerExecAttach(ctx, execID, types_container.ExecAttachOptions{})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// kill only writes to stderr if it failed
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, conn.Reader); err != nil {
+		return err
+	}
+	if output := strings.TrimSpace(stderr.String()); len(output) > 0 {
+		return fmt.Errorf("%s", output)
+	}
+	return nil
+}
+
+// namespacePID maps the pid of the local host to the pid in the innermost pid namespace of the process.
+// Pids that are not found in /proc are returned unchanged.
+func namespacePID(pid int) int {
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return pid
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "NSpid:" {
+			if nsPid, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+				return nsPid
+			}
+		}
+	}
+	return pid
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCPUTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"0:00", 0},
+		{"1:05", 65 * time.Second},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"2-01:02:03", 49*time.Hour + 2*time.Minute + 3*time.Second},
+		{"1m2.5s", time.Minute + 2500*time.Millisecond},
+		{"", -1},
+		{"n/a", -1},
+	}
+	for _, test := range tests {
+		if actual := parseCPUTime(test.value); actual != test.expected {
+			t.Errorf("parseCPUTime(%q): expected %v, got %v", test.value, test.expected, actual)
+		}
+	}
+}
+
+func TestUpdateCPUPercent(t *testing.T) {
+	previous := map[int]Process{
+		1: {PID: 1, CPUPercent: 80, CPUTime: 10 * time.Second},
+		2: {PID: 2, CPUPercent: 5, CPUTime: -1},
+	}
+	processes := []Process{
+		{PID: 1, CPUPercent: 80, CPUTime: 11 * time.Second},
+		{PID: 2, CPUPercent: 5, CPUTime: -1},
+		{PID: 3, CPUPercent: 20, CPUTime: time.Second},
+	}
+	updateCPUPercent(processes, previous, 4*time.Second)
+
+	// usage since the previous listing replaces the lifetime average, unless the cpu time is unknown
+	for i, expected := range []float64{25, 5, 20} {
+		if processes[i].CPUPercent != expected {
+			t.Errorf("expected %v%% cpu of process %d, got %v", expected, processes[i].PID, processes[i].CPUPercent)
+		}
+	}
+}
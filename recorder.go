@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	types_container "github.com/docker/docker/api/types/container"
+	types_event "github.com/docker/docker/api/types/events"
+	"os"
+	"sync"
+	"time"
+)
+
+// kinds of entries of a recording
+const (
+	RecordList    = "list"
+	RecordInspect = "inspect"
+	RecordStats   = "stats"
+	RecordEvent   = "event"
+)
+
+// RecordEntry is a single response of a runtime captured in a recording
+type RecordEntry struct {
+	Time int64           `json:"t"` // unix time in nanoseconds the response was received
+	Host string          `json:"h"`
+	Kind string          `json:"k"`
+	ID   string          `json:"id,omitempty"` // id of the container of inspect and stats entries
+	OS   string          `json:"os,omitempty"` // operating system of the runtime of stats entries
+	Data json.RawMessage `json:"d"`
+}
+
+// Recorder writes the responses of runtimes to a gzip compressed file of JSON lines, one RecordEntry per line.
+// Inspect results are only written when they differ from the previous one of the container.
+type Recorder struct {
+	mutex        sync.Mutex
+	file         *os.File
+	gzip         *gzip.Writer
+	buffer       *bufio.Writer
+	encoder      *json.Encoder
+	lastInspects map[string][]byte // last inspect result written by host and container id
+	entries      int
+	lastFlush    time.Time
+}
+
+// RecordFlushInterval is the interval buffered entries are written to the recording file in
+const RecordFlushInterval = 5 * time.Second
+
+// recorder captures the responses of all backends if set
+var recorder *Recorder = nil
+
+// NewRecorder creates the recording file
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		file:         file,
+		gzip:         gzip.NewWriter(file),
+		lastInspects: make(map[string][]byte),
+	}
+	r.buffer = bufio.NewWriter(r.gzip)
+	r.encoder = json.NewEncoder(r.buffer)
+	return r, nil
+}
+
+// Record appends an entry with given payload to the recording
+func (r *Recorder) Record(host string, kind string, id string, osType string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Printf("Failed to record %s of %s: %v\n", kind, host, err)
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.encoder == nil {
+		return
+	}
+	if kind == RecordInspect {
+		key := host + "/" + id
+		if bytes.Equal(r.lastInspects[key], data) {
+			return
+		}
+		r.lastInspects[key] = data
+	}
+	now := time.Now()
+	entry := RecordEntry{Time: now.UnixNano(), Host: host, Kind: kind, ID: id, OS: osType, Data: data}
+	if err := r.encoder.Encode(entry); err != nil {
+		fmt.Printf("Failed to record %s of %s: %v\n", kind, host, err)
+		return
+	}
+	r.entries++
+	if now.Sub(r.lastFlush) >= RecordFlushInterval {
+		// keep the recording readable if the HUD doesn't exit gracefully
+		r.lastFlush = now
+		if err := r.buffer.Flush(); err == nil {
+			_ = r.gzip.Flush()
+		}
+	}
+}
+
+// Close flushes and closes the recording file
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.encoder == nil {
+		return nil
+	}
+	r.encoder = nil
+	fmt.Printf("Recorded %d entries to %s\n", r.entries, r.file.Name())
+	if err := r.buffer.Flush(); err != nil {
+		_ = r.file.Close()
+		return err
+	}
+	if err := r.gzip.Close(); err != nil {
+		_ = r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// recordingBackendFactory wraps the backends of the factory to capture their responses if a recorder is set
+func recordingBackendFactory(host string, newBackend BackendFactory) BackendFactory {
+	if recorder == nil {
+		return newBackend
+	}
+	return func() (ContainerBackend, error) {
+		backend, err := newBackend()
+		if err != nil {
+			return nil, err
+		}
+		return &RecordingBackend{ContainerBackend: backend, host: host, recorder: recorder}, nil
+	}
+}
+
+// RecordingBackend captures container lists, inspect results, stats and events of the wrapped backend
+type RecordingBackend struct {
+	ContainerBackend
+	host     string
+	recorder *Recorder
+}
+
+func (b *RecordingBackend) ContainerList(ctx context.Context, all bool) ([]types_container.Summary, error) {
+	containers, err := b.ContainerBackend.ContainerList(ctx, all)
+	if err == nil && all {
+		b.recorder.Record(b.host, RecordList, "", "", containers)
+	}
+	return containers, err
+}
+
+func (b *RecordingBackend) ContainerInspect(ctx context.Context, id string) (types_container.InspectResponse, error) {
+	inspect, err := b.ContainerBackend.ContainerInspect(ctx, id)
+	if err == nil {
+		b.recorder.Record(b.host, RecordInspect, id, "", inspect)
+	}
+	return inspect, err
+}
+
+func (b *RecordingBackend) ContainerStats(ctx context.Context, id string) (StatsStream, error) {
+	stream, err := b.ContainerBackend.ContainerStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingStatsStream{StatsStream: stream, backend: b, id: id}, nil
+}
+
+func (b *RecordingBackend) Events(ctx context.Context) (<-chan types_event.Message, <-chan error) {
+	events, errs := b.ContainerBackend.Events(ctx)
+	recorded := make(chan types_event.Message)
+	go func() {
+		defer close(recorded)
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				b.recorder.Record(b.host, RecordEvent, "", "", event)
+				select {
+				case recorded <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return recorded, errs
+}
+
+// recordingStatsStream captures every stats sample of the wrapped stream
+type recordingStatsStream struct {
+	StatsStream
+	backend *RecordingBackend
+	id      string
+}
+
+func (s *recordingStatsStream) Next() (*types_container.StatsResponse, error) {
+	stats, err := s.StatsStream.Next()
+	if err == nil {
+		s.backend.recorder.Record(s.backend.host, RecordStats, s.id, s.OSType(), stats)
+	}
+	return stats, err
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	types_container "github.com/docker/docker/api/types/container"
+	types_event "github.com/docker/docker/api/types/events"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ReplaySpeeds are the speeds a recording can be replayed at
+	ReplaySpeeds = []float64{1, 10, 60}
+	// ReplayPollInterval is the interval replayed streams check the replay clock in
+	ReplayPollInterval = 50 * time.Millisecond
+
+	errReplayReadOnly = errors.New("not available while replaying a recording")
+
+	// replaying is set while a recording is replayed, replayed container data is neither notified nor exported then
+	replaying = false
+)
+
+// Recording holds the entries of a recording file by host, each sorted by time
+type Recording struct {
+	Start time.Time
+	End   time.Time
+	hosts map[string]*recordedHost
+}
+
+// recordedHost holds the entries of a single runtime, inspect results and stats are indexed by container id
+type recordedHost struct {
+	entries  []RecordEntry
+	inspects map[string][]RecordEntry
+	stats    map[string][]RecordEntry
+}
+
+// LoadRecording reads a recording file written by Recorder.
+// A truncated file, e.g. of a HUD that didn't exit gracefully, is read up to the last complete entry.
+func LoadRecording(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	entries := make(map[string][]RecordEntry)
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+	for {
+		var entry RecordEntry
+		if err := decoder.Decode(&entry); err != nil {
+			if err != io.EOF {
+				fmt.Printf("Stopped reading recording %s at invalid entry: %v\n", path, err)
+			}
+			break
+		}
+		entries[entry.Host] = append(entries[entry.Host], entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: recording is empty", path)
+	}
+
+	recording := &Recording{hosts: make(map[string]*recordedHost)}
+	for host, hostEntries := range entries {
+		sort.SliceStable(hostEntries, func(i int, j int) bool {
+			return hostEntries[i].Time < hostEntries[j].Time
+		})
+		start, end := time.Unix(0, hostEntries[0].Time), time.Unix(0, hostEntries[len(hostEntries)-1].Time)
+		if recording.Start.IsZero() || start.Before(recording.Start) {
+			recording.Start = start
+		}
+		if end.After(recording.End) {
+			recording.End = end
+		}
+		recorded := &recordedHost{
+			entries:  hostEntries,
+			inspects: make(map[string][]RecordEntry),
+			stats:    make(map[string][]RecordEntry),
+		}
+		for _, entry := range hostEntries {
+			switch entry.Kind {
+			case RecordInspect:
+				recorded.inspects[entry.ID] = append(recorded.inspects[entry.ID], entry)
+			case RecordStats:
+				recorded.stats[entry.ID] = append(recorded.stats[entry.ID], entry)
+			}
+		}
+		recording.hosts[host] = recorded
+	}
+	return recording, nil
+}
+
+// Hosts returns the names of the recorded runtimes
+func (r *Recording) Hosts() []string {
+	hosts := make([]string, 0, len(r.hosts))
+	for host := range r.hosts {
+		hosts = append(hosts, host)
+	}
+	slices.Sort(hosts)
+	return hosts
+}
+
+// ReplayClock is the time of a replayed recording, it runs at a chosen speed and can be paused or moved
+type ReplayClock struct {
+	mutex    sync.Mutex
+	start    time.Time
+	end      time.Time
+	position time.Time // time of the recording at anchor
+	anchor   time.Time // wall time the position got set at
+	speed    float64
+	paused   bool
+	onSeek   []func()
+}
+
+// NewReplayClock creates a clock running at normal speed from the start of the recording
+func NewReplayClock(recording *Recording) *ReplayClock {
+	return &ReplayClock{
+		start:    recording.Start,
+		end:      recording.End,
+		position: recording.Start,
+		anchor:   time.Now(),
+		speed:    1,
+	}
+}
+
+// now returns the current time of the recording, caller must hold the mutex
+func (c *ReplayClock) now() time.Time {
+	if c.paused {
+		return c.position
+	}
+	elapsed := time.Duration(float64(time.Since(c.anchor)) * c.speed)
+	if position := c.position.Add(elapsed); position.Before(c.end) {
+		return position
+	}
+	return c.end
+}
+
+// Now returns the current time of the recording, it stops at the end of the recording
+func (c *ReplayClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now()
+}
+
+// Range returns start and end of the recording
+func (c *ReplayClock) Range() (time.Time, time.Time) {
+	return c.start, c.end
+}
+
+func (c *ReplayClock) Speed() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.speed
+}
+
+func (c *ReplayClock) SetSpeed(speed float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.position, c.anchor = c.now(), time.Now()
+	c.speed = speed
+}
+
+func (c *ReplayClock) Paused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.paused
+}
+
+func (c *ReplayClock) SetPaused(paused bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.position, c.anchor = c.now(), time.Now()
+	c.paused = paused
+}
+
+// OnSeek registers a callback called after the clock got moved
+func (c *ReplayClock) OnSeek(seeked func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onSeek = append(c.onSeek, seeked)
+}
+
+// Seek moves the clock to given time of the recording
+func (c *ReplayClock) Seek(position time.Time) {
+	c.mutex.Lock()
+	if position.Before(c.start) {
+		position = c.start
+	}
+	if position.After(c.end) {
+		position = c.end
+	}
+	c.position, c.anchor = position, time.Now()
+	callbacks := slices.Clone(c.onSeek)
+	c.mutex.Unlock()
+
+	for _, seeked := range callbacks {
+		seeked()
+	}
+}
+
+// replayBackendFactory creates backends replaying the recorded responses of given host
+func replayBackendFactory(recording *Recording, host string, clock *ReplayClock) BackendFactory {
+	return func() (ContainerBackend, error) {
+		return NewReplayBackend(recording, host, clock), nil
+	}
+}
+
+// ReplayBackend serves the recorded responses of a runtime as they were at the time of the replay clock.
+// History before the time of the clock is delivered at once when following stats of a container.
+// Controlling containers is not available.
+type ReplayBackend struct {
+	*recordedHost
+	host  string
+	clock *ReplayClock
+}
+
+var _ ContainerBackend = &ReplayBackend{}
+
+func NewReplayBackend(recording *Recording, host string, clock *ReplayClock) *ReplayBackend {
+	recorded, ok := recording.hosts[host]
+	if !ok {
+		recorded = &recordedHost{}
+	}
+	return &ReplayBackend{recordedHost: recorded, host: host, clock: clock}
+}
+
+// recordedUntil returns the entries recorded up to given time
+func recordedUntil(entries []RecordEntry, t time.Time) []RecordEntry {
+	idx := sort.Search(len(entries), func(i int) bool {
+		return entries[i].Time > t.UnixNano()
+	})
+	return entries[:idx]
+}
+
+// latestInspect returns the last inspect result of the container recorded up to given time
+func (b *ReplayBackend) latestInspect(id string, t time.Time) (RecordEntry, bool) {
+	if inspects := recordedUntil(b.inspects[id], t); len(inspects) > 0 {
+		return inspects[len(inspects)-1], true
+	}
+	return RecordEntry{}, false
+}
+
+func (b *ReplayBackend) Name() string {
+	return "replay"
+}
+
+func (b *ReplayBackend) Ping(ctx context.Context) error {
+	return nil
+}
+
+// ContainerList returns the containers known at the time of the clock with the state of their latest inspect result
+func (b *ReplayBackend) ContainerList(ctx context.Context, all bool) ([]types_container.Summary, error) {
+	now := b.clock.Now()
+	ids := make([]string, 0)
+	for _, entry := range recordedUntil(b.entries, now) {
+		switch entry.Kind {
+		case RecordList:
+			var containers []types_container.Summary
+			if err := json.Unmarshal(entry.Data, &containers); err == nil {
+				ids = ids[:0]
+				for _, container := range containers {
+					ids = append(ids, container.ID)
+				}
+			}
+		case RecordInspect:
+			if !slices.Contains(ids, entry.ID) {
+				ids = append(ids, entry.ID)
+			}
+		case RecordEvent:
+			var event types_event.Message
+			if err := json.Unmarshal(entry.Data, &event); err == nil && event.Type == types_event.ContainerEventType && event.Action == types_event.ActionDestroy {
+				ids = slices.DeleteFunc(ids, func(id string) bool { return id == event.Actor.ID })
+			}
+		}
+	}
+
+	containers := make([]types_container.Summary, 0, len(ids))
+	for _, id := range ids {
+		entry, ok := b.latestInspect(id, now)
+		if !ok {
+			continue
+		}
+		var inspect types_container.InspectResponse
+		if err := json.Unmarshal(entry.Data, &inspect); err != nil || inspect.ContainerJSONBase == nil {
+			continue
+		}
+		summary := types_container.Summary{ID: id, Names: []string{inspect.Name}, Image: inspect.Image}
+		if inspect.State != nil {
+			summary.State = inspect.State.Status
+		}
+		if inspect.Config != nil {
+			summary.Labels = inspect.Config.Labels
+		}
+		if all || summary.State == "running" {
+			containers = append(containers, summary)
+		}
+	}
+	return containers, nil
+}
+
+func (b *ReplayBackend) ContainerInspect(ctx context.Context, id string) (types_container.InspectResponse, error) {
+	var inspect types_container.InspectResponse
+	entry, ok := b.latestInspect(id, b.clock.Now())
+	if !ok {
+		return inspect, fmt.Errorf("no such container in recording: %s", id)
+	}
+	err := json.Unmarshal(entry.Data, &inspect)
+	return inspect, err
+}
+
+func (b *ReplayBackend) ContainerInspectRaw(ctx context.Context, id string) ([]byte, error) {
+	entry, ok := b.latestInspect(id, b.clock.Now())
+	if !ok {
+		return nil, fmt.Errorf("no such container in recording: %s", id)
+	}
+	return entry.Data, nil
+}
+
+// ContainerStats replays the stats samples of the container, starting the recent duration before the time of the clock
+func (b *ReplayBackend) ContainerStats(ctx context.Context, id string) (StatsStream, error) {
+	samples := b.stats[id]
+	from := len(recordedUntil(samples, b.clock.Now().Add(-tunables().RecentDuration)))
+	return &replayStatsStream{ctx: ctx, clock: b.clock, samples: samples[from:]}, nil
+}
+
+func (b *ReplayBackend) ContainerLogs(ctx context.Context, id string, options types_container.LogsOptions) (io.ReadCloser, error) {
+	return nil, errors.New("logs are not part of a recording")
+}
+
+// Events replays the events recorded after the time of the clock when the clock passes them
+func (b *ReplayBackend) Events(ctx context.Context) (<-chan types_event.Message, <-chan error) {
+	events := make(chan types_event.Message)
+	errs := make(chan error, 1)
+	next := len(recordedUntil(b.entries, b.clock.Now()))
+	go func() {
+		defer close(events)
+		defer close(errs)
+		for ; next < len(b.entries); next++ {
+			entry := b.entries[next]
+			if entry.Kind != RecordEvent {
+				continue
+			}
+			for b.clock.Now().UnixNano() < entry.Time {
+				select {
+				case <-time.After(ReplayPollInterval):
+				case <-ctx.Done():
+					return
+				}
+			}
+			var event types_event.Message
+			if err := json.Unmarshal(entry.Data, &event); err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}()
+	return events, errs
+}
+
+func (b *ReplayBackend) ContainerStart(ctx context.Context, id string) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerStop(ctx context.Context, id string) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerRestart(ctx context.Context, id string) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerPause(ctx context.Context, id string) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerUnpause(ctx context.Context, id string) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerKill(ctx context.Context, id string, signal string) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerTop(ctx context.Context, id string, arguments []string) (types_container.TopResponse, error) {
+	return types_container.TopResponse{}, errReplayReadOnly
+}
+
+func (b *ReplayBackend) TopPIDScope() PIDScope {
+	return ContainerPIDs
+}
+
+func (b *ReplayBackend) ContainerExecCreate(ctx context.Context, id string, options types_container.ExecOptions) (string, error) {
+	return "", errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerExecAttach(ctx context.Context, execID string, options types_container.ExecAttachOptions) (types.HijackedResponse, error) {
+	return types.HijackedResponse{}, errReplayReadOnly
+}
+
+func (b *ReplayBackend) ContainerExecResize(ctx context.Context, execID string, height uint, width uint) error {
+	return errReplayReadOnly
+}
+
+func (b *ReplayBackend) Close() error {
+	return nil
+}
+
+// replayStatsStream delivers recorded stats samples once the replay clock passed them
+type replayStatsStream struct {
+	ctx     context.Context
+	clock   *ReplayClock
+	samples []RecordEntry
+	next    int
+	osType  string
+}
+
+func (s *replayStatsStream) OSType() string {
+	return s.osType
+}
+
+// Next blocks until the clock passed the next sample, returns io.EOF when the stream got closed
+func (s *replayStatsStream) Next() (*types_container.StatsResponse, error) {
+	for s.next >= len(s.samples) || s.clock.Now().UnixNano() < s.samples[s.next].Time {
+		select {
+		case <-time.After(ReplayPollInterval):
+		case <-s.ctx.Done():
+			return nil, io.EOF
+		}
+	}
+	entry := s.samples[s.next]
+	s.next++
+	var stats types_container.StatsResponse
+	if err := json.Unmarshal(entry.Data, &stats); err != nil {
+		return nil, err
+	}
+	s.osType = entry.OS
+	return &stats, nil
+}
+
+func (s *replayStatsStream) Close() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"github.com/AllenDang/giu"
+	"time"
+)
+
+const (
+	ReplayWindowWidth  = 520
+	ReplayWindowHeight = 90
+)
+
+// renderReplayWindow builds the window controlling the replay clock, it is shown while replaying a recording
+func (a *App) renderReplayWindow() {
+	if a.replayClock == nil {
+		return
+	}
+
+	start, end := a.replayClock.Range()
+	now := a.replayClock.Now()
+	if !a.replaySeeking {
+		a.replayPosition = int32(now.Sub(start).Seconds())
+	}
+	paused := a.replayClock.Paused()
+	playLabel := "Pause"
+	if paused {
+		playLabel = "Play"
+	}
+
+	speedButtons := make([]giu.Widget, 0, len(ReplaySpeeds))
+	for _, speed := range ReplaySpeeds {
+		speedButtons = append(speedButtons,
+			giu.RadioButton(fmt.Sprintf("%gx", speed), a.replayClock.Speed() == speed).OnChange(func() {
+				a.replayClock.SetSpeed(speed)
+			}),
+		)
+	}
+
+	giu.Window("Replay").Size(ReplayWindowWidth, ReplayWindowHeight).Layout(
+		giu.Row(
+			giu.Button(playLabel+"##replay").OnClick(func() {
+				a.replayClock.SetPaused(!paused)
+			}),
+			giu.Row(speedButtons...),
+			giu.Label(fmt.Sprintf("%s / %s",
+				now.Local().Format(time.DateTime), end.Sub(start).Round(time.Second))),
+		),
+		giu.SliderInt(&a.replayPosition, 0, int32(end.Sub(start).Seconds())).
+			Label("##replayPosition").
+			Size(-1).
+			Format(formatReplayOffset(time.Duration(a.replayPosition)*time.Second)).
+			OnChange(func() {
+				a.replaySeeking = true
+			}),
+		giu.Custom(func() {
+			// seek once the slider got released, every seek restarts following the containers
+			if a.replaySeeking && !giu.IsItemActive() {
+				a.replaySeeking = false
+				a.replayClock.Seek(start.Add(time.Duration(a.replayPosition) * time.Second))
+			}
+		}),
+	)
+}
+
+// formatReplayOffset returns the offset into the recording as slider text, e.g. "+1h2m3s"
+func formatReplayOffset(offset time.Duration) string {
+	return "+" + offset.String()
+}
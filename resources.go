@@ -14,3 +14,12 @@ var restartIconData = []byte(
 
 var stopIconData = []byte(
 	"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00d\x00\x00\x00d\b\x06\x00\x00\x00p\xe2\x95T\x00\x00\x00\tpHYs\x00\x00=\x84\x00\x00=\x84\x01լ\xaft\x00\x00\x00\x19tEXtSoftware\x00www.inkscape.org\x9b\xee<\x1a\x00\x00\n\xfdIDATx\x9c\xed\x9dm\x8c\\e\x15\xc7\x7fgf\xb7-l\xbb\be\x8dQ@\xa8\xbc\x14\x89\xf2\x12M\xd4n\xc9\x16k\fI\x81\xee:w\xba\xed\xf2b\x02\xb6P\xa9u\xadF\x8d1\bhJ\b\x14ʛPP\x02\xeb\xb6˽\xd3\x05j\x13>\bt\xc9\x16\xfc\x02)\xa2Hi  \x16\x8d\x94\x15\xe8\x1b\xddv\xef=~\x98\v,\xb8\x9d\xe7\xb9w\xee\xcc\xdeٝ\xdf\xd79s\xce3\xf7?\xf7\xde\xe7\xe5<\xe7\x11U\xa5NzȌw\x03\xea|\x9c\xba )\xa3.Hʨ\v\x922ꂤ\x8c\xba )\xa3.H\xcah\x18\xef\x06D@<\x8f\xe3U9\x158M\x84\x99\xaa4\x89\xf0)`zh\xb3W\x95wE\xd8'\xc2\xdbA\xc0\x0e\x11v8\x0eo\x8cc\xbb#!i\x1d\x18z\x1e\xd3E\x98\xab\xca<\xa0\r\xf8\"\xd0\x14\xd3\xdd>\xe0EU\x06D\xd8r\xe0\x00\x83\x97\\¾\xa4ښ$\xa9\x12\xc4\xf38J\x04G\x95.`\x0e\xd0X\xa1P\x87\x80\xad@/Pp\x1cޫP\x9cȤB\x10\xcf\xe3<`)p\x110\xad\xca\xe1\xdfW\xe5Q`]>ϖ*\xc7\xfe?\xc6U\x10\xd7e\xbe\b\xd7\x01_\x1f\xb7F|\x9cm\"\xac\xce\xe5(\x00\xe3ra\xc6E\x90\xf0\x8eX\x03\x9cY\xf5\xe0vl\x13\xe1G\xb9\x1c\x03\xd5\x0e\\UA<\x8f\xcf\x007\x02\x17\x03R\xb5\xc0\xf1ٜ\xcdrUG\a;\xab\x15\xb0j\xe3\x90B\x81e\xc0\xcb\xc0%Ԇ\x18\x00\v|\x9f\xbf\x15\n\\Q\xad\x80\x15\xbfCz{i\x9e:\x95{U\xc9'\xe8v\x04x\r\xd8\r\xbc\x03\x1fva\x9b\x80\xa3\x81f\xe0$\x12\x1cg\x89\xb0a\xca\x14\x96]x!{\x92\xf29f\x9cJ\n\xb2q#g\a\x01.pr\x19n\x02\xe0y\x11\x9eT\xe5\x99L\x86\x97\x86\x86xu\xe9R\x0e\x95\xfaҺu4Μ\xc9\x17\x82\x80\xd3U\x99#\xc2<\xe0,\xca{*\xec\b\x02\xf2\x8b\x16\xf1\x972|\x94\xa4b\x82\xb8.\xdf\x16a#\xf1\x06s\x010(\xc2\x03\xaa<\xea8\xfc7\x896y\x1eǨ\xb2P\x84ˀ\xb9\xc4{t\xee\x05:\x1c\x87?%ѦOR\x11A<\x8f%\xc0\xfd\xc0\x94\x88_ݫ\xca=\r\r\xdc\xde\xd1\xc1?\x12o\xd8(\xfa\xfa81\x9be\x05\xb0\x8c\xe8\x7f\x9a\x83\"\\\x96\xcbїt\xbb\x12\x17\xc4\xf3\xb8\n\xb8\x83h\x8f\x86}\"\xac\xc9fY\xdb\xde\xceP\xa2\r2\xb0a\x03\xc7f\xb3\xac\x14\xa1\x9bh\xc2\x04\xaa|?\x9f\xe7\xee$ۓ\xa8 \xae\xcbb\x11\xfe@416g\xb3\\]\xe9;\u0084\xe7\xf19`5\xc5^\xa0-*\xc2ws9\x1eL\xaa\x1d\x89\t\x12\xbe3\xfe\x88\xfd\xfc\xd3[\"\\\x9e˱9\x91\x06$\x84\xebr\x81\b\xbf\x03Z,\xbfr\x10X\x90\xd4;%\x11A\xc2\xde\xd4 \xf6\xb7\xfc\x80\xef\xd3\xd5\xd9ɿ\xca\x0e^\x01»e=p\xae\xe5W\xf6\x04\x01s\x93\xe8}\x95=0\xec\xed\xa59\xec\xdaZ\x89\xa1ʭ\xc0\xfc\xb4\x8a\x01\xe08\xbc\xd9\xd2\xc27)\xbe\vm\x98\x91\xc9\xe0n\xdaČrc\x97=pjl\xe4.\xec\xc6\x19\xaa\xcau\xf9<\xbf*7f5hkc\x04X\xe1\xba\xec\x14a5\xe6.\xf2\xa9\a\x0fr\x1f\xb0\xa8\x9c\xb8e=\xb2\n\x05\x96\xa9Z\xf52T\x95\xe5I\xf7H\xaaE\xd8s\xbc\x13\x8bq\x8b\b\xdf\xcb\xe5\xb8/n\xac\u0602\x84\x13\x85ہ\xa3,\xcc\x7f\xee8\xdc\x10+PJ\xf0<~\x01\xfc\xda\xc2tw6\xcb\xec\x8e\x0e\xfe\x1d'N\xecw\x88\bk\xb0\x13cm\xad\x8b\x01\xe08\xfc\x06\xbbwJs\x10pc\xdc8\xb1\x04q]\xceU\xa5\xd3\xc2\xf4)`U\x9c\x18i\xa4\xa5\x85n`\xd0d\xa7\xcaŮ˼81\xe2\b\"\"܆\xf9y\xfa\x96\xef\xb3\xc4q\xf0c\xc4H%mm\x8cd\xb3,\x01\xde6يp\x131\xe6\xca\"\v\xe2\xba,\xc0b\xa5O\x84\xcb\xd3ܵ\x8dKG\a;E\xac\xd6G\xceq]Ώ\xea?\xb2 \"\xfc\xcc\xc2lc\xdaF\xe0I\x92\xcb\xf1(\xb0\xc9d'\xc2/\xa3\xfa\x8e$H\xb8\x16\xfe\r\x83\xd9^ߧ;jCj\r\xdfg%\xb0\xdf`\xf6\xb5\xa8\uf4a8w\xc8R\x93\x81\b\xb7tv\xf2ψ~k\x8e\xceN^We\xad\x85\xa9\xf1\x9a\x8d\xc6Z\x90\xde^\x9a\x81\v\rf\xfb\x0e\x1d\xe2\xb6(\r\xa8e\x1a\x1b\xb9\x99\xe2\x82\xd5a\x11a\xa1\xe7Y\r\x0f\x80\b\x8246\xb2\b8\xc2\x10\xfc\xb7\x8b\x17\x9b{ \x13\x85\xf6v\x86D\xb8\xd7`6M\x95\xef\xd8\xfa\xb4\x9e\xcb\x12a\x89\xc1DGF\xb8\xd3\xd6_)<\x8fVU\xe6$\xe1\xebp\x88\xf0\xb4㰵\\?\xaa\xdc\x0e\xfc\x90\x12]\\\x11\xba\x80\xdf\xdb\xf8\xb3\x12\xc4\xf3\x98\x0e\xc6\v\xf4Tg'\xaf\xdb\xf83\xa1\xca|\x11\xaeI\xc2W\x89\x18\xd7B\xf9\x828\x0e\xafy\x1e[)\xae\xd1\x1f\x8e֞\x1e\x9al\x12\xbc\xad\x1eY\"\xccŰ\xf0$\x92ܪY\xad\xa1J\x8f\xc1d\xca\x11G\xd8\xdd\xf1V\x82\x84[\x02J\x11\x84\t˓\x12\xdf\xe7a\x8a\x992\xa58\xcfƗ\xedK\xbd\xcd\xf0\xf9\xf3I\xa5\xea\xd4\"aG\xe6\x85R6\xaa\xc6k\b\xd8\t\"\xc0\xe9\x06\x9b'l\x82MdD\x8c[\x19\xce\xc0bn\xcb(H_\x1f\xc7\xf1і\xb11Q\xe5\x19\x93\x9fI\x80\xa9\x830\xdd\xf3\xf8\xacɉQ\x90l\x96\xd9\x166\xdbM6\x13\x1d\x11^\xb60;\xcdd`\x14D\x84S\f&#CC\xbcjј\t͑G\xf2\n\x94^j\b7\xac\x96\xc4(\x88*\xc7\x1aL^7%>O\x06\xce?\x9fa0&\xfb\x19s\xbdl\xee\x10Sjˤ\xed]\x8d\xc1;\x86\xcfK\xbe\x8b\xc1B\x90 0\nRrrm\x92Qr\xef\x88ş۪\xdbkR\xb5\xa2\x1bXj\x8cݥ>LJ\x90:U\xc4F\x10\xd3#\xa9\xec\xf4\xc9\tDs\xa9\x0fU\xcdO\x13\xa3 \x99\x8cщ\xf1E5\x89(\xf9\xe7LD\x10\v'ǘ|L\"\x8e.\xf5\xa1ş۪\xdbkZ\x01<\xd1\xf3\"o]\x9bp<\xf6\x18S\x81ϗ\xb2\t\x02\xf3j\xaaM\xb7w\x87\xc1\xa4!\x93a\x96\xc9\xcfDg\xff~N\x06\xb2\x063\xe3\xf4\x8a\x8d F'A`\x9c\r\x9e\xf0\xf8\xbey\xceO\xc4\xf8\xe76\v\xd2\xd9\xc9N̙\x15\xa6\\\xad\tO&c\\\x11\xdc\xe38\xe6LN\x9bn\xaf\x02\x7f/i\xa0v\xaba\x13\x19\x8bk\xf0\"\x16\x15\x86l\x97p\a\f&gy\xde\xe4\xedmy\x1e-\xc0\x97\ff\x036\xbel\x93\x1cL\xaba\x19U\x16\xda\xf8\x9a\xa0\xb4c\xbe\x96O\xda8\xb2\x12\xe4\xc0\x01\x06)n\xff=,\"\\j\xe3k\x82b\xda\xdb~\xf0\xc0\x01\xbbUU\xeb-m\x9eǓP2\xfbD}\x9fYI\xe4f\xb9.\xf33\x19\xe6\x97\xeb\xa7\x14A\xc0\xe3\xf9<\x8f\x97맿\x9fY\xbe\xcf+\x94^/\x7f\xc2q\xec~O\x94\xcc\xc5\xf5\x86t \xc9d\xb8\x1a\xf8\xb1\xad\xcf\xc3\x11^\xa8\xb2/V5\x18\x19a\x85H\xe9\xe4\x05\x11zm\xfdY\xcf\xf6\x0e\x0f\xe3bH\xbf\x17\xe1\xca\xf0\x057)ذ\x81c-6ＯJ\xbf\xadOkA\xba\xbaحjܤҤ\xca\x0fl}\xd6:\r\r\xac\xc20\xb9*\xc2#Q\xca\xd0F]\x0fYg2\x10\xa1\xdb\xf38!\xa2ߚ\xc3\xf38\tXiaj\xbcf\xa3\x89$HX\xd7\xf6i\x83Y\x13pk\x14\xbf5\xcaZ\f\xdb3\x80?G\xadl\x1ag\xc5p\xb5\x85M\xbb\xebrA\f\xdf5\x81\xeb\xb2\x10̿O\x84\xeb\xa3\xfa\x8eS\xc9A<\x8f瀳\rv\xbb\x80\xb3\x1d\x877\xa3\x06H3}}\x1c\x9fͲ\r\x98i0}\xceq\xf8JT\xffq\xee\x10\x15a%\xe6y\x99\x16`\xfd\xc0@M\x9d\xc0P\x92\x81\x01\x1a\xb2Y\xd6c\x16CE\xe2u\xffc%9\xe4r\f\xaa\xb2\xde\xc2\xf4\xdc]\xbb\xb8%N\x8c4\xb2k\x17\xb7\x01\xad&;Uz\xe2VŎ\x9du\xe2\xfb\xac\x02\u07b50\xbd:,\xdcR\xd3\x14\n\\\x03\\ea\xfa^C\x83\xd5^\xfe1\x89-\xc8\xe2\xc5\xfcG\x84\x9fX\x9a_\x1f\x968\xaaI\n\x05\x96\xab\xda\xd5\xf9\x12\xa1;n% (3/+\x97㾰\xe8\xa5\t\x01\xee*\x14j\xaf*\x90\xeb\xf2SU\xbbͬ\xaa\xf4\xe5r\xdc_N\xbc\xb2k.n\xdaČ\xe1a\x9e\x05sfw\xc8\x1d--t\x87\x15\xdbR\xcb\xc0\x00\r\xe1;\xc3\xf6\xce\xde\x0e|\xd5q\xcaK\xadM\xa4\b\xe6C\x0fqf&\xc3V\xecs\xb4\x06\xb3Y\x96T\xf3ԁ(\x84]\xdb\r\x98w\x1e\x7f\xc0n\xa0\xd5q\xf8k\xb9\xb1\x13I%\r\xabqv`X3\x19\xc5\\\xdfg[\xa1\xc0EI\xc4O\x12\xd7ea8ΰ\x15cX\x84\x8e$Ā\x84\v)\x17\nt\xaa\xd2K\xc4Bʾϊ\xa4\xf6\xb8ǥ\xbf\x9f\xe3|\x9f5\x80\x13\xe1k\x81*\x8b\xf3yܤڑx\xa9q\xd7\xe5J\x11\xee$\x9a(\xfbUY+\xc2-\x8eîD\x1bd\xe0\xe1\x87\xf9\xf4\xc8\b\xdd\x14'\nMsS\xa3\tDX\x9e\xcbqO\x92\xed\xa9H1\xfe\xf0Ny\x80\xe8\xc5\xf8\xf7\x89\xb0N\x95\xdb\x1d\x87\xd7\x12o\xd8(\xfa\xfb\x99\x15..-\x05\x8e\x8c\xf8\xf5aU.M\xf2\xce\xf8\x80\x8a\x1dW\xe1y|\v\xe8'^2\xb6\x02[Ex\xf0\xd0!\x1eI\xaa\xa0MXx\xbf=\\\xff\x9fC\xbc\xe3*v\x8bБ\xcbUf+xE\x0ft\t{_.\xf6]\xe2\xb1\b\x80\x17D\xd8\x12\x04<-\xc2K\xc0+\x8eS\xba\x03\xe1yL\xc9d8\xc5\xf7\x99\x9d\xc90'̛\xfa2\xe5\x1d\xb7\xb4\x1d\xc8'\xf5\x02\x1f\x8b\x8a\x1fy\x14\x8eS\xee\x06c5\xa1(\x8c\x00oP\xdc\xd3\xf7.\x1feVΠX\xba\xf6\x18\xe0\x04̹\xb6Q\xe8\x01\x96\x97;\xce0Q\xb5S\xda\n\x05\xaeP\xe5&\xecj\xfd\xa6\x89wDXU\xee\bܖ\xaami\v\xcboϦ\xf8O\x1b\xff\xe3E\xed\xf0FF8\xbdZb\xc08\x1d,Y(Ц\xca\xcd\xc09U\x0fnǳ\xe1\xc1\x92Ƣ\xc9I3\xaeG\xafz\x1e\xad\xc0\xb5X\x96.\xaa\x02ϨrC>\xcff&\xd3ѫ\x9f$,\xa5\xbaT\x84\x8b\x8868K\x82\xf7Ex\x04X7\x1eG\xad~\x92T\b\xf2\x01\xbd\xbd476\x92\vk\x14\xb6\x12}`i\xcbA\x8a\xc7\xf2\xf5\x0e\x0f\xb3\xb1\xab\xab\xf4\xfe\xf2j\x92*AF\xd3\xd3C\xd3ԩ\xb4f2\xcc\v\x8b\x7f\x9dA\xfc\x1d\xbf{(\xee\xcf\x18\x10a˴il]\xb0\xc0X\x04y\\H\xad c\xd1\xdf\xcfqA\xc0i\xaa\x9c*\xc2L`\xba*G\xab~x\xdc\xd2\xdeL\xa68.Qe\x88➾\x97k)\xf3\xa5\xa6\x04\x99\f\xd4Kk\xa4\x8c\xba )\xa3.Hʨ\v\x922ꂤ\x8c\xba )\xa3.H\xca\xf8\x1fzv\x83Uk\xffr\f\x00\x00\x00\x00IEND\xaeB`\x82")
+
+var startIconData = []byte(
+	"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00d\x00\x00\x00d\b\x06\x00\x00\x00p\xe2\x95T\x00\x00\x03tIDATx\xda\xed\x9d\xcdm\xc4 \x10\x85S\x02%\xb8\x04\x97@\t.\xc1%\xb8\x04w\xe0\x12\xf6\xbe\x17Jp\t.!%P\xc2Ƒ\x88d\xad\xa2\x04\xcc\xc0<\xecwx\xa7Uv\x1d\x7f\xf0\x98\xe1g\xf8x>_\x1f\x14\x8e\xf8\x12\b\x84\"\x10\x02\xa1\b\x84@(\x02!\x108\xf5\xbb\xec\xae\xf9 \xb7k\rro\x9f\xd9\xf07\x04\" \xb3kص\x84\x97\xfd\xca\xd4\x1a\xbek\b\xdfM \x91\x10&!\x001\x80&48(\x0fb\x83ݼ\x94\xe4\xc23\xdc\x1eȸkS\x04\xf1\xae-<\xd3\xed\x80X0\x10\xbf\x81\xb1w\x00\xd2)[\xd3\x19+\xeb\xae\n\xe4{\x00\xf5\r\xc1\xf8\x91\x0f\xcf~\x19 \xa6P\xaf\xd8\x0e\xf9ǻ\xb6B\xbdŴ\x0e\xe4;)\xfb\x14\nQ\xe7\x90Ct\x89\x169\x84\xbf\x95\b\xa5?K'\x9a\xa5#(\x9f\xd9\"G\xe1Vi\xc2w\xbaL\v\x1b[\x032f\xfc\xb3s\xa5\x81\xb4\v\xbfu\xb6ь\xad\x00\x193@hd\xcd&\x03̈\x0e\xe4\f\x8c\a\xc8\xf4\x85\tϢ\nE\x13\x86G\x99\xae\xf8%i\xf5ZP$\xa3)\x8f\x16BV\fսT\xf4%\xf5\xf0)\xa1\xed\xd2\xd0\xfaĒ\x18\x12\x1b\x04 N;2\xa90\x01\x9a\xd2\xf3U\x81L\x17\x87q\x06ʤ\x05\xa4K\x187Z\x86\x91\n\xc5\xe7\xe4Q5\xacj\xb9\x00\x8c\xd41\xc5\xd5\x06bky*\xa0b\x1b\xa2\xad\td\x8b\xec\xba\xe6\x82@L\xa4Uo\xb5\x80\x8c%[\xc8\x1f\xe3U\x0f\x96<\x16\x19;K\xf5\x8eG\xa1\xec\x19)8x\x94\xe8%%ZF\t\xab\xb2\xa0s_^\xda)J\fhs\x05\x8b\xd8@,l\x96\x0elR[\x84\xd6@nk/\x14\t\xf7\x12S\x02Ȥ\xd4;\xfe\xb3Jm\v\x9b%\xb3\xf7\x94\x1f\x8eY\x93\ue522\x1aM\v\xeb\"\xf7\x04\x88\x021\xcaI`l01\x02'\x8bF\x12Ƞ<_\x15\x1b\xf7kYXLn6H\x02Y$\a\xae\xc2@4,,\xc6A\x16I \xab\x94GV\x02\xa2aa\"\xef(\xf6Ǵ\xa2\xab\x1c \xb5-,&\xda\x12\x01\xd2K\xf9\xa3\x12\x90Z\x16\x163\xce\xf6\x12@\xacb\xb8+\x05\xa4\x86\x85ń\xbfV\x02\x88HW\x04\x00R\xc3²\xad]\x02\xc8\xd6\x18\x90\x92\x16\xb6!\x00Y\x1b\x04R\xca\xc2\xd6\x1a@\xdcE\x81\x94\xb0\xb0\xff\x808\t \xebŁHZX\xf6\xbb\"\x90\x06\x81в\xc0,\x8b\x83:ؠΰ\xb7\xb1\xb0\x97\x89a\xe5ĐS'`S'\x9c\\\x04\x9b\\\xe4\xf4;\xd8\xf4;\x17\xa8\x00\x17\xa8\xb8\x84\v\xb6\x84\xcbM\x0e`\x9b\x1c\xb8\r\bl\x1b\x107ʁm\x94\xe3VR\xc0\xad\xa4\xdcl\r\xb6ٚ\xc7\x11\xc0\x8e#\xf0\xc0\x0e\xe0\x81\x1d\x1ei\x03;\xd2\xc6C\x9f\x80\x87>y,\x1a\xecX4\v\a\x00\x16\x0e`i\r\xb0\xd2\x1a,>\x03X|\x86\xe5\x99\xc0\xca3\xb1\x80\x19`\x013\x96\xf8\x03,\xf1\xc7\"\x98`E0Y&\x16\xb0L,\v)\xb3\xd48K\x8d\x97\x86\xc2b\xfcO^Wq\x9b\xeb*\x8e\xd1\x17/t\x01\x02r&\x84\xe4\x95G\x95\xc4K\xc1\xc0\x80\xfcX\b\xaf\xcd\x03\x02rL\xbex\xb1$\x10\x90c$ƫW\x81\x80\x1c{\f/'~\xf2\xfan^ߝ\b\x87\x17܃\xab\x0f\xd62\x1f\xe4\x0e\xf9\x87{\xfb̂m\x1f\xba\x1c\x90ˋ/\x81@(\x02!\x10\x8a@\b\x84\"\x90\x1b\xe8\v\x19ED\xd1{\xbf\x89\x84\x00\x00\x00\x00IEND\xaeB`\x82")
+
+var pauseIconData = []byte(
+	"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00d\x00\x00\x00d\b\x06\x00\x00\x00p\xe2\x95T\x00\x00\x03YIDATx\xda\xed\x9d\xc1\x8d\x830\x10E\xb7\x04\x97@\t)\xc1%P\x82K\xa0\x04:\xa0\x84\xdcs\xa1\x04J\xa0\x84-\x81\x12\xb2\xac\xe4H(\x8av\xc70\x1e\x7f\xc3?\xfcS\x14\xe2\xf0\xec\xf1\x8c=\x1e\x7f=\x1e\xcf/\nG|\t\x04B\x11\b\x81P\x04B \x14\x81\x10\b\x9cn\xab\xfc\xaa~\xa3q\xd5\x145\xbe}\xe6\xe3w\bDAnU\xbbj\x88/\xfbyPS|V\x1b\x9fM B\b\x9d\x12\x00\t\xa0\x0e\r\x0eJC|47\xcfB\x1ac\x1b.\x0f$\xac\x9a\v\x82x\xd7\x1c\xdbt9 \x1e\f\xc4'0\xfe\n@\x9a¦i\x8f)k\xce\n\xe4w\x02]*\x82\xf1\xd2\x12\xdb~\x1a .Ө\x987\xf1ǻ\xe6L\xa3\xc5\xd5\x0e\xe47(\xfbVrQ\xfb\x18C4\x89&\xb2\x8d\xdf\xd5p\xa5\xbfs\a\x9a\xb9=\xa8\xe5`\x8f\fʽ\xd2\xc5g\x8e\aMX\xa8\rH8\xf0g{\xa3\x89\xb4\x89\xbf\xb5\xb7ӄZ\x80\x84\x03 JD\xcd\xee\x00\x98\x80\x0ed\x0f\x8c;\xc8\xf2\x85\x8bm)\n\xa5$\x8c\x05e\xb9\xe2Cк\x94\x82\xa2\xe9M-h.\xa4\xa1\xab\xbehy_Z\x8dOqm\x87\x8a\xf6'\x86D\x97\xd8!\x00\x19K{&\x06\v\xa0)#\xbf(\x90\xee\xe40\xf6@\xe9J\x01i\x12捚a\xa4BY\x8e\xc4Q\x16\xa6j8\x01\x8c\xd49e\xb4\x06\xe2\xadl*\xa0\xa4\x1d\xd1[\x02\x99\x85Cם\x10\x88\x13\x9a\xea\xd9\nH\xc8\xd9C*\x91\xcf5w\xe6\x1a\x1d\xf7\v$\xb5\xdds\x8c\x92\x1c=C\xc3T\x85?6\x9f&\xa1\xa30\xfc\xf3\x8c`d\xba|N \x92\t\xadW\xe8}\xbd`\xc3\xea\xbfgL\x00\xedLvlR{\x84\xd5D^\v\x10\xe9(q9\x80tF\xa3\xa3& \xd2Q\xd2\xe5\x00\"ٓn.\b\xa4\x11\xe6\x04\xa8\x02q\xc6A`M@\xa4s\xab\xd3\x04\xd2\x1a\xafW\xd5\x06D\x12\x9b\xb5\x9a@\x06͉\xeb\x84@$\x16d\xd0\x042i\xd9ȓ\x02Q{G\xd2\x1f{\x1a\xff\xb9\x1a\x81H\xbc-\x15 7-\xfbxr \x92y\xf6\xa6\x01\xc4\x1b\xba\xbb5\x03\x91\xb8\xbf^\x03\x88\xcaP\xbc\x00\x10\x15Ӯ\xf1rf\x02\x11\xaf\x84\x9b\x00\x99\bD\xef75\xa2P\x02\x91\xff\xe6\xa8\x01d\"\x10\xbbX\x84@*\x04B\x93\x05f\xb28\xa9\x83M\xeat{+s{\x19\x18\x1a\a\x86\\:\x01[:\xe1\xe2\"\xd8\xe2\"\x97\xdf\xc1\x96߹A\x05\xb8A\xc5-\\\xb0-\\&9\x80%90\r\b,\r\x88\x89r`\x89rL%\x05L%e\xb25X\xb25\x8f#\x80\x1dG\xe0\x81\x1d\xc0\x03;<\xd2\x06v\xa4\x8d\x87>\x01\x0f}\xf2X4رh\x16\x0e\x00,\x1c\xc0\xd2\x1a`\xa55X|\x06\xb0\xf8\f\xcb3\x81\x95gb\x013\xc0\x02f,\xf1\aX\xe2\x8fE0\xc1\x8a`\xb2L,`\x99X\x16Rf\xa9q\x96\x1a\xcf\r\x85\xc5\xf8\x1f\xbc\xae\xe22\xd7Ul\xbd/^\xe8\x02\x04d\x8f\v\xc9+\x8f\x8c\xc4K\xc1\xc0\x80\xbcL\b\xaf\xcd\x03\x02\xb2\r\xbex\xb1$\x10\x90\xad'ƫW\x81\x80lG\f/'~\xf0\xfan^ߝ\b\x87\x17܃\xeb\x16MK\xbfѸ\x89?Ʒ\xcf|\xee@\xee\xea@N/\xbe\x04\x02\xa1\b\x84@(\x02!\x10\x8a@.\xa0\x1fJ\v\x9b\x99\xf2\xd3\xc6i\x00\x00\x00\x00IEND\xaeB`\x82")
+
+var killIconData = []byte(
+	"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00d\x00\x00\x00d\b\x06\x00\x00\x00p\xe2\x95T\x00\x00\x03\x9fIDATx\xda\xed\x9d\xc1\x8d\xc3 \x10E\xb7\x04Jp\t)\x81\x12\\\x02%\xb8\x04w\xe0\x12r\xf7\x85\x12R\x82K\xd8\x12\\B\xd6+\x11Ɋ\xa2\xdd\xc1\f\xf0\xb1\xff២8\xc4\x0f\x86\x19\x18\x86\xafy~~Q8\xe2K \x10\x8a@\b\x84\"\x10\x02\xa1\b\x84@\xe0t\xdbd7\x8d;\xf9M\x8f \xff\xf6\x99\r\xdf!\x10\x05\x99M\xfd\xa6)\xbc\xecg\xa2\x1e\xe1Y}x6\x81\b!\fJ\x00$\x80\x0648(\r\xb1\xc1\xdc<+ɇ6\\\x1e\x88۴T\x04\xf1\xae%\xb4\xe9r@,\x18\x88O`\xec\x15\x80t\x95M\xd3\x11S֝\x15\xc8\xef\x04\xba6\x04\xe3\xa55\xb4\xfd4@L\xa6Q\xb1\xec\xe2\x8fw-\x99F\x8bi\x1d\xc8oP\xf6\xad䢎!\x86\xe8\"Md\x1f\xbe\xab\xe1J\x7f\xe7\x0e4s{Pkb\x8ftʽ҄g\xfaD\x13\xe6Z\x03\xe2\x12\xfe\xecXh\"\xed\xc2o\x1d\xed4\xae\x15 .\x01D\x8d\xa8\xd9$\x80q\xe8@\x8e\xc0\xb8\x83,_\x98Ж\xaaPj\xc2XQ\x96+>\x04\xadk-(\x9a\xdeԊ\xe6B\x16t\xd5W-\xefK\xab\xf11\xae\xed\xd4\xd0\xfe\xc4\x14\xe9\x12\x1b\x04 \xbe\xb6gR`\x014f\xe4W\x052\x9c\x1c\xc6\x11(C- ]ļ\xd12\x8cX(kJ\x1cU\xc2TM'\x80\x11;\xa7\xf8\xd2@l)\x9b\n(iG\xb4%\x81,¡kN\b\xc4\bM\xf5R\n\x88\xcb\xd9C\x1a\x91\xcd5w\xe6\x1a\x1d\xf7\v$\xb5\xdds\x8c\x92\x1c=㬦\xea\xa8\xe9\xb29\x81H&\xb4\xf1B\xa9\x9f\xa3\xb6c\x13\xdb#j\x8c\x8eW\x06c\xeas\xfaLm\x93\x8c\x12\x93\x03\xc8Pat\x98ݜ\xe5\x14\x82\xba%\x03\x94Q3z\x8f\xf9aɞt\x97\tFJ\xc4\xef>L\xb4\x9aP:aN\x80*\x10S8\b4\x7fxsN\xc1E׆\xe2\xb5\xccV\x8c\xfd-\xb5^e\x04\xae\xb5S\x88\x974\xa1Hb\xb3^\x13Ȥ9q%\u0090@\x91\x06\xaf\x8bb\xbbU\xd6\xf4\xb4揇RO\xeb\xe7\xf4\xad\xd3ح\xe4^\xa9\xed*\xefH\xfac%\xbd+\x97\x00\xc5\xcd\xf5\x12\x14$ޖ\n\x90[\xc1^\x96\xf2bk\u0090\x8e\xee\x9b\x06\x10[\xd8\xddMM\xb6\xab\xb5a&q\x7f\xad\x06\x10\x95\xa1\b\x04%\xe7\xeee\xb2i\xd7\x00\xb2\xccy\u05cb\\#0$+\xe1E\x80<\xe6\xfc\x8bx\xae\x01\x18\x12OK\x05\x88\a\x00\x92\n\xa5T\x92\xc5\x7f@\xbc\x06\x90\a\b\x90\xa3PJf\xbc$\xbf+\x02i\x10\bM\x16\x98\xc9\xe2\xa4\x0e6\xa9\xd3\xedm\xcc\xede`X80\xe4\xd2\t\xd8\xd2\t\x17\x17\xc1\x16\x17\xb9\xfc\x0e\xb6\xfc\xce\r*\xc0\r*n\xe1\x82m\xe12\xc9\x01,Ɂi@`i@L\x94\x03K\x94c*)`*)\x93\xad\xc1\x92\xady\x1c\x01\xec8\x02\x0f\xec\x00\x1e\xd8\xe1\x916\xb0#m<\xf4\tx\xe8\x93Ǣ\xc1\x8eE\xb3p\x00`\xe1\x00\x96\xd6\x00+\xad\xc1\xe23\x80\xc5gX\x9e\t\xac<\x13\v\x98\x01\x160c\x89?\xc0\x12\x7f,\x82\tV\x04\x93eb\x01\xcbĲ\x902K\x8d\xb3\xd4x\x89$7\x16\xe3\xcf\xec.\xf2\xba\n  /\xef\x8b\x17\xba\x00\x019\xe2B\xf2ʣB\xe2\xa5``@^&\x84\xd7\xe6\x01\x01\xd9\a_\xbcX\x12\b\xc8\xde\x13\xe3ի@@\xf6#\x86\x97\x13ϼ\xbe\x9b\xd7w\x1f\xc8X\xe4\x05\xf7\xc0\xba\x05\xd32\xee\xe4w\xf1\x87\x7f\xfb\xcc\xe6\x0e\xe4\xae\x0e\xe4\xf4\xe2K \x10\x8a@\b\x84\"\x10\x02\xa1\b\xe4\x02\xfa\x01\x1eKSq/v\x0f\x18\x00\x00\x00\x00IEND\xaeB`\x82")
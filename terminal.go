@@ -0,0 +1,479 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	DefaultTerminalRows = 24
+	DefaultTerminalCols = 80
+
+	// TerminalDefaultColor is the color index of the default foreground or background color
+	TerminalDefaultColor = -1
+)
+
+// TerminalColors are the 16 colors of the ANSI palette
+var TerminalColors = []color.RGBA{
+	{0, 0, 0, 255}, {205, 49, 49, 255}, {13, 188, 121, 255}, {229, 229, 16, 255},
+	{36, 114, 200, 255}, {188, 63, 188, 255}, {17, 168, 205, 255}, {229, 229, 229, 255},
+	{102, 102, 102, 255}, {241, 76, 76, 255}, {35, 209, 139, 255}, {245, 245, 67, 255},
+	{59, 142, 234, 255}, {214, 112, 214, 255}, {41, 184, 219, 255}, {255, 255, 255, 255},
+}
+
+// TerminalCell is a character on the screen of the terminal with its colors as index into TerminalColors
+type TerminalCell struct {
+	Char rune
+	Fg   int
+	Bg   int
+}
+
+type terminalParserState int
+
+const (
+	terminalGround terminalParserState = iota
+	terminalEscape
+	terminalCSI
+	terminalOSC
+	terminalCharset
+)
+
+// Terminal emulates the screen of a VT100 like terminal, it implements io.Writer to be fed with the output of a TTY.
+// Supported are cursor movement, erasing, scroll regions and colors, other sequences are ignored.
+type Terminal struct {
+	mutex sync.Mutex
+
+	rows  int
+	cols  int
+	cells [][]TerminalCell
+
+	cursorRow    int
+	cursorCol    int
+	savedRow     int
+	savedCol     int
+	cursorHidden bool
+	wrapPending  bool // next printed character wraps to the next line
+	scrollTop    int
+	scrollBottom int
+
+	fg      int
+	bg      int
+	bold    bool
+	reverse bool
+
+	state   terminalParserState
+	params  []byte
+	pending []byte // incomplete utf-8 sequence
+}
+
+// NewTerminal creates a terminal of given size
+func NewTerminal(rows int, cols int) *Terminal {
+	t := &Terminal{fg: TerminalDefaultColor, bg: TerminalDefaultColor}
+	t.resize(rows, cols)
+	return t
+}
+
+// Size returns the number of rows and columns of the terminal
+func (t *Terminal) Size() (int, int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.rows, t.cols
+}
+
+// Resize changes the size of the terminal keeping the top left part of the screen
+func (t *Terminal) Resize(rows int, cols int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.resize(rows, cols)
+}
+
+func (t *Terminal) resize(rows int, cols int) {
+	rows, cols = max(1, rows), max(1, cols)
+	cells := make([][]TerminalCell, rows)
+	for row := range cells {
+		cells[row] = t.blankLine(cols)
+		if row < len(t.cells) {
+			copy(cells[row], t.cells[row])
+		}
+	}
+	t.rows, t.cols, t.cells = rows, cols, cells
+	t.scrollTop, t.scrollBottom = 0, rows-1
+	t.cursorRow = min(t.cursorRow, rows-1)
+	t.cursorCol = min(t.cursorCol, cols-1)
+	t.wrapPending = false
+}
+
+// Screen returns a copy of the screen, the cursor position and whether the cursor is visible
+func (t *Terminal) Screen() ([][]TerminalCell, int, int, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	screen := make([][]TerminalCell, t.rows)
+	for row := range t.cells {
+		screen[row] = append([]TerminalCell(nil), t.cells[row]...)
+	}
+	return screen, t.cursorRow, t.cursorCol, !t.cursorHidden
+}
+
+// Text returns the text on the screen without trailing blanks
+func (t *Terminal) Text() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	lines := make([]string, len(t.cells))
+	for row, cells := range t.cells {
+		var line strings.Builder
+		for _, cell := range cells {
+			line.WriteRune(cell.Char)
+		}
+		lines[row] = strings.TrimRight(line.String(), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// Write interprets the output of a TTY
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, b := range p {
+		t.feed(b)
+	}
+	return len(p), nil
+}
+
+func (t *Terminal) feed(b byte) {
+	switch t.state {
+	case terminalEscape:
+		t.escape(b)
+	case terminalCSI:
+		switch {
+		case b >= 0x30 && b <= 0x3f:
+			t.params = append(t.params, b)
+		case b >= 0x40 && b <= 0x7e:
+			t.csi(b)
+			t.state = terminalGround
+		case b < 0x20:
+			t.control(b)
+		}
+	case terminalOSC:
+		// operating system commands like setting the window title are ignored
+		if b == 0x07 {
+			t.state = terminalGround
+		} else if b == 0x1b {
+			t.state = terminalEscape
+		}
+	case terminalCharset:
+		t.state = terminalGround
+	default:
+		if len(t.pending) == 0 && b < 0x20 || b == 0x7f {
+			t.control(b)
+			return
+		}
+		t.pending = append(t.pending, b)
+		if utf8.FullRune(t.pending) {
+			r, _ := utf8.DecodeRune(t.pending)
+			t.pending = t.pending[:0]
+			t.put(r)
+		}
+	}
+}
+
+func (t *Terminal) control(b byte) {
+	switch b {
+	case 0x1b:
+		t.state = terminalEscape
+	case '\r':
+		t.cursorCol = 0
+		t.wrapPending = false
+	case '\n', 0x0b, 0x0c:
+		t.lineFeed()
+	case '\b':
+		t.cursorCol = max(0, t.cursorCol-1)
+		t.wrapPending = false
+	case '\t':
+		t.cursorCol = min(t.cols-1, (t.cursorCol/8+1)*8)
+	}
+}
+
+func (t *Terminal) escape(b byte) {
+	t.state = terminalGround
+	switch b {
+	case '[':
+		t.params = t.params[:0]
+		t.state = terminalCSI
+	case ']':
+		t.state = terminalOSC
+	case '(', ')':
+		t.state = terminalCharset
+	case '7':
+		t.savedRow, t.savedCol = t.cursorRow, t.cursorCol
+	case '8':
+		t.moveTo(t.savedRow, t.savedCol)
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.cursorCol = 0
+		t.lineFeed()
+	case 'M':
+		if t.cursorRow == t.scrollTop {
+			t.scrollDown(1)
+		} else {
+			t.moveTo(t.cursorRow-1, t.cursorCol)
+		}
+	case 'c':
+		t.fg, t.bg, t.bold, t.reverse, t.cursorHidden = TerminalDefaultColor, TerminalDefaultColor, false, false, false
+		t.eraseDisplay(2)
+		t.resize(t.rows, t.cols)
+		t.moveTo(0, 0)
+	}
+}
+
+// csi executes a control sequence with given final byte
+func (t *Terminal) csi(final byte) {
+	private := len(t.params) > 0 && t.params[0] == '?'
+	params := t.csiParams()
+	param := func(idx int, def int) int {
+		if idx < len(params) && params[idx] > 0 {
+			return params[idx]
+		}
+		return def
+	}
+	if private {
+		if (final == 'h' || final == 'l') && len(params) > 0 {
+			switch params[0] {
+			case 25:
+				t.cursorHidden = final == 'l'
+			case 47, 1047, 1049:
+				// alternate screen is emulated by clearing the screen
+				t.eraseDisplay(2)
+				t.moveTo(0, 0)
+			}
+		}
+		return
+	}
+
+	switch final {
+	case 'A':
+		t.moveTo(t.cursorRow-param(0, 1), t.cursorCol)
+	case 'B', 'e':
+		t.moveTo(t.cursorRow+param(0, 1), t.cursorCol)
+	case 'C', 'a':
+		t.moveTo(t.cursorRow, t.cursorCol+param(0, 1))
+	case 'D':
+		t.moveTo(t.cursorRow, t.cursorCol-param(0, 1))
+	case 'E':
+		t.moveTo(t.cursorRow+param(0, 1), 0)
+	case 'F':
+		t.moveTo(t.cursorRow-param(0, 1), 0)
+	case 'G', '`':
+		t.moveTo(t.cursorRow, param(0, 1)-1)
+	case 'd':
+		t.moveTo(param(0, 1)-1, t.cursorCol)
+	case 'H', 'f':
+		t.moveTo(param(0, 1)-1, param(1, 1)-1)
+	case 'J':
+		t.eraseDisplay(param(0, 0))
+	case 'K':
+		t.eraseLine(param(0, 0))
+	case 'L':
+		if t.cursorRow >= t.scrollTop && t.cursorRow <= t.scrollBottom {
+			top := t.scrollTop
+			t.scrollTop = t.cursorRow
+			t.scrollDown(param(0, 1))
+			t.scrollTop = top
+		}
+	case 'M':
+		if t.cursorRow >= t.scrollTop && t.cursorRow <= t.scrollBottom {
+			top := t.scrollTop
+			t.scrollTop = t.cursorRow
+			t.scrollUp(param(0, 1))
+			t.scrollTop = top
+		}
+	case '@':
+		line := t.cells[t.cursorRow]
+		n := min(param(0, 1), t.cols-t.cursorCol)
+		copy(line[t.cursorCol+n:], line[t.cursorCol:])
+		t.blank(line[t.cursorCol : t.cursorCol+n])
+	case 'P':
+		line := t.cells[t.cursorRow]
+		n := min(param(0, 1), t.cols-t.cursorCol)
+		copy(line[t.cursorCol:], line[t.cursorCol+n:])
+		t.blank(line[t.cols-n:])
+	case 'X':
+		line := t.cells[t.cursorRow]
+		t.blank(line[t.cursorCol:min(t.cols, t.cursorCol+param(0, 1))])
+	case 'S':
+		t.scrollUp(param(0, 1))
+	case 'T':
+		t.scrollDown(param(0, 1))
+	case 'm':
+		t.sgr(params)
+	case 'r':
+		top, bottom := param(0, 1)-1, param(1, t.rows)-1
+		if top < bottom && bottom < t.rows {
+			t.scrollTop, t.scrollBottom = top, bottom
+		}
+		t.moveTo(0, 0)
+	case 's':
+		t.savedRow, t.savedCol = t.cursorRow, t.cursorCol
+	case 'u':
+		t.moveTo(t.savedRow, t.savedCol)
+	}
+}
+
+func (t *Terminal) csiParams() []int {
+	var params []int
+	for _, p := range strings.Split(strings.TrimLeft(string(t.params), "?>="), ";") {
+		value, _ := strconv.Atoi(p)
+		params = append(params, value)
+	}
+	return params
+}
+
+// sgr applies "select graphic rendition" parameters, i.e. colors and attributes
+func (t *Terminal) sgr(params []int) {
+	for idx := 0; idx < len(params); idx++ {
+		switch p := params[idx]; {
+		case p == 0:
+			t.fg, t.bg, t.bold, t.reverse = TerminalDefaultColor, TerminalDefaultColor, false, false
+		case p == 1:
+			t.bold = true
+		case p == 22:
+			t.bold = false
+		case p == 7:
+			t.reverse = true
+		case p == 27:
+			t.reverse = false
+		case p >= 30 && p <= 37:
+			t.fg = p - 30
+		case p == 39:
+			t.fg = TerminalDefaultColor
+		case p >= 40 && p <= 47:
+			t.bg = p - 40
+		case p == 49:
+			t.bg = TerminalDefaultColor
+		case p >= 90 && p <= 97:
+			t.fg = p - 90 + 8
+		case p >= 100 && p <= 107:
+			t.bg = p - 100 + 8
+		case (p == 38 || p == 48) && idx+2 < len(params) && params[idx+1] == 5:
+			// 256 color palette, only the ANSI colors are supported
+			if c := params[idx+2]; c < len(TerminalColors) {
+				if p == 38 {
+					t.fg = c
+				} else {
+					t.bg = c
+				}
+			}
+			idx += 2
+		case (p == 38 || p == 48) && idx+1 < len(params) && params[idx+1] == 2:
+			// true color is not supported
+			idx += 4
+		}
+	}
+}
+
+func (t *Terminal) put(r rune) {
+	if t.wrapPending {
+		t.cursorCol = 0
+		t.lineFeed()
+	}
+	fg, bg := t.fg, t.bg
+	if t.bold && fg >= 0 && fg < 8 {
+		fg += 8
+	}
+	if t.reverse {
+		fg, bg = bg, fg
+		if fg == TerminalDefaultColor {
+			fg = 0
+		}
+		if bg == TerminalDefaultColor {
+			bg = 7
+		}
+	}
+	t.cells[t.cursorRow][t.cursorCol] = TerminalCell{Char: r, Fg: fg, Bg: bg}
+	if t.cursorCol == t.cols-1 {
+		t.wrapPending = true
+	} else {
+		t.cursorCol++
+	}
+}
+
+func (t *Terminal) moveTo(row int, col int) {
+	t.cursorRow = min(t.rows-1, max(0, row))
+	t.cursorCol = min(t.cols-1, max(0, col))
+	t.wrapPending = false
+}
+
+func (t *Terminal) lineFeed() {
+	t.wrapPending = false
+	if t.cursorRow == t.scrollBottom {
+		t.scrollUp(1)
+	} else if t.cursorRow < t.rows-1 {
+		t.cursorRow++
+	}
+}
+
+// scrollUp moves the lines of the scroll region up, blank lines are added at the bottom
+func (t *Terminal) scrollUp(n int) {
+	region := t.cells[t.scrollTop : t.scrollBottom+1]
+	n = min(n, len(region))
+	copy(region, region[n:])
+	for idx := len(region) - n; idx < len(region); idx++ {
+		region[idx] = t.blankLine(t.cols)
+	}
+}
+
+// scrollDown moves the lines of the scroll region down, blank lines are added at the top
+func (t *Terminal) scrollDown(n int) {
+	region := t.cells[t.scrollTop : t.scrollBottom+1]
+	n = min(n, len(region))
+	copy(region[n:], region)
+	for idx := 0; idx < n; idx++ {
+		region[idx] = t.blankLine(t.cols)
+	}
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for row := t.cursorRow + 1; row < t.rows; row++ {
+			t.blank(t.cells[row])
+		}
+	case 1:
+		t.eraseLine(1)
+		for row := 0; row < t.cursorRow; row++ {
+			t.blank(t.cells[row])
+		}
+	default:
+		for row := range t.cells {
+			t.blank(t.cells[row])
+		}
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	line := t.cells[t.cursorRow]
+	switch mode {
+	case 0:
+		t.blank(line[t.cursorCol:])
+	case 1:
+		t.blank(line[:t.cursorCol+1])
+	default:
+		t.blank(line)
+	}
+}
+
+func (t *Terminal) blank(cells []TerminalCell) {
+	for idx := range cells {
+		cells[idx] = TerminalCell{Char: ' ', Fg: TerminalDefaultColor, Bg: t.bg}
+	}
+}
+
+func (t *Terminal) blankLine(cols int) []TerminalCell {
+	line := make([]TerminalCell, cols)
+	t.blank(line)
+	return line
+}
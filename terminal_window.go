@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/AllenDang/giu"
+	"github.com/AllenDang/imgui-go"
+	"golang.design/x/clipboard"
+	"image"
+	"image/color"
+	"io"
+	"sync"
+)
+
+const (
+	TerminalWindowWidth  = 700
+	TerminalWindowHeight = 450
+)
+
+var (
+	TerminalForegroundColor = color.RGBA{R: 220, G: 220, B: 220, A: 255}
+	TerminalBackgroundColor = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+	TerminalCursorColor     = color.RGBA{R: 170, G: 170, B: 255, A: 160}
+)
+
+// terminalKeys are the special keys and the input sent to the TTY when pressed
+var terminalKeys = []struct {
+	key   giu.Key
+	input string
+}{
+	{giu.KeyEnter, "\r"},
+	{giu.KeyTab, "\t"},
+	{giu.KeyBackspace, "\x7f"},
+	{giu.KeyEscape, "\x1b"},
+	{giu.KeyUp, "\x1b[A"},
+	{giu.KeyDown, "\x1b[B"},
+	{giu.KeyRight, "\x1b[C"},
+	{giu.KeyLeft, "\x1b[D"},
+	{giu.KeyHome, "\x1b[H"},
+	{giu.KeyEnd, "\x1b[F"},
+	{giu.KeyInsert, "\x1b[2~"},
+	{giu.KeyDelete, "\x1b[3~"},
+	{giu.KeyPageUp, "\x1b[5~"},
+	{giu.KeyPageDown, "\x1b[6~"},
+}
+
+// TerminalWindow is a window showing an interactive shell executed in a container
+type TerminalWindow struct {
+	id       string
+	title    string
+	terminal *Terminal
+
+	mutex   sync.Mutex
+	session *ExecSession
+	ended   string
+
+	cancel context.CancelFunc
+
+	open  bool
+	input string // buffer of the hidden input widget capturing typed characters
+}
+
+// NewTerminalWindow creates an open terminal window, cancel is called when the window gets closed
+func NewTerminalWindow(id string, title string, cancel context.CancelFunc) *TerminalWindow {
+	return &TerminalWindow{
+		id:       id,
+		title:    title,
+		terminal: NewTerminal(DefaultTerminalRows, DefaultTerminalCols),
+		cancel:   cancel,
+		open:     true,
+	}
+}
+
+// Attach shows the output of the session until the executed process exits
+func (w *TerminalWindow) Attach(session *ExecSession) {
+	w.mutex.Lock()
+	w.session = session
+	w.mutex.Unlock()
+
+	rows, cols := w.terminal.Size()
+	if err := session.Resize(context.Background(), rows, cols); err != nil {
+		fmt.Printf("Failed to resize terminal %s: %v\n", w.id, err)
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := session.Read(buf)
+		if n > 0 {
+			_, _ = w.terminal.Write(buf[:n])
+			giu.Update()
+		}
+		if err == io.EOF {
+			w.End(nil)
+			return
+		} else if err != nil {
+			w.End(err)
+			return
+		}
+	}
+}
+
+// End marks the end of the session, err is shown if set
+func (w *TerminalWindow) End(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err != nil {
+		w.ended = fmt.Sprintf("Shell ended: %v", err)
+	} else {
+		w.ended = "Shell ended"
+	}
+
+	giu.Update()
+}
+
+// Close terminates the session
+func (w *TerminalWindow) Close() {
+	w.cancel()
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.session != nil {
+		w.session.Close()
+	}
+}
+
+// IsOpen returns false when the user closed the window
+func (w *TerminalWindow) IsOpen() bool {
+	return w.open
+}
+
+// send writes given input to the TTY of the session
+func (w *TerminalWindow) send(input string) {
+	w.mutex.Lock()
+	session := w.session
+	w.mutex.Unlock()
+	if session == nil || len(input) == 0 {
+		return
+	}
+	if _, err := session.Write([]byte(input)); err != nil {
+		fmt.Printf("Failed to write to terminal %s: %v\n", w.id, err)
+	}
+}
+
+func (w *TerminalWindow) copyScreen() {
+	fmt.Printf("Copied screen of terminal %s to clipboard\n", w.id)
+	clipboard.Write(clipboard.FmtText, []byte(w.terminal.Text()))
+}
+
+func (w *TerminalWindow) paste() {
+	w.send(string(clipboard.Read(clipboard.FmtText)))
+}
+
+// resize adapts the size of the terminal and the TTY to the available region
+func (w *TerminalWindow) resize(rows int, cols int) {
+	if currentRows, currentCols := w.terminal.Size(); currentRows == rows && currentCols == cols {
+		return
+	}
+	w.terminal.Resize(rows, cols)
+	w.mutex.Lock()
+	session := w.session
+	w.mutex.Unlock()
+	if session != nil {
+		go func() {
+			if err := session.Resize(context.Background(), rows, cols); err != nil {
+				fmt.Printf("Failed to resize terminal %s: %v\n", w.id, err)
+			}
+		}()
+	}
+}
+
+// handleKeys sends special keys and control characters to the TTY, Ctrl+Shift+C / Ctrl+Shift+V copy and paste
+func (w *TerminalWindow) handleKeys() {
+	ctrl := giu.IsKeyDown(giu.KeyLeftControl) || giu.IsKeyDown(giu.KeyRightControl)
+	shift := giu.IsKeyDown(giu.KeyLeftShift) || giu.IsKeyDown(giu.KeyRightShift)
+	if ctrl && shift && giu.IsKeyPressed(giu.KeyC) {
+		w.copyScreen()
+		return
+	}
+	if ctrl && shift && giu.IsKeyPressed(giu.KeyV) {
+		w.paste()
+		return
+	}
+	if ctrl {
+		for key := giu.KeyA; key <= giu.KeyZ; key++ {
+			if giu.IsKeyPressed(key) {
+				w.send(string(rune(key - giu.KeyA + 1)))
+			}
+		}
+	}
+	for _, k := range terminalKeys {
+		if giu.IsKeyPressed(k.key) {
+			w.send(k.input)
+		}
+	}
+}
+
+// drawScreen draws the cells and the cursor of the terminal at the current cursor position
+func (w *TerminalWindow) drawScreen(charWidth float32, charHeight float32) {
+	screen, cursorRow, cursorCol, cursorVisible := w.terminal.Screen()
+	canvas := giu.GetCanvas()
+	origin := giu.GetCursorScreenPos()
+	cellPos := func(row int, col int) image.Point {
+		return origin.Add(image.Pt(int(float32(col)*charWidth), int(float32(row)*charHeight)))
+	}
+	colorOf := func(idx int, def color.RGBA) color.RGBA {
+		if idx >= 0 && idx < len(TerminalColors) {
+			return TerminalColors[idx]
+		}
+		return def
+	}
+
+	canvas.AddRectFilled(origin, cellPos(len(screen), len(screen[0])), TerminalBackgroundColor, 0, 0)
+	for row, cells := range screen {
+		// draw runs of cells with same colors as a single text
+		for start := 0; start < len(cells); {
+			end := start + 1
+			for end < len(cells) && cells[end].Fg == cells[start].Fg && cells[end].Bg == cells[start].Bg {
+				end++
+			}
+			if cells[start].Bg != TerminalDefaultColor {
+				canvas.AddRectFilled(cellPos(row, start), cellPos(row+1, end), colorOf(cells[start].Bg, TerminalBackgroundColor), 0, 0)
+			}
+			runes := make([]rune, 0, end-start)
+			for _, cell := range cells[start:end] {
+				runes = append(runes, cell.Char)
+			}
+			canvas.AddText(cellPos(row, start), colorOf(cells[start].Fg, TerminalForegroundColor), string(runes))
+			start = end
+		}
+	}
+	if cursorVisible {
+		canvas.AddRectFilled(cellPos(cursorRow, cursorCol), cellPos(cursorRow+1, cursorCol+1), TerminalCursorColor, 0, 0)
+	}
+}
+
+// Render builds the window of the terminal
+func (w *TerminalWindow) Render() {
+	w.mutex.Lock()
+	status := w.ended
+	w.mutex.Unlock()
+
+	giu.Window(fmt.Sprintf("%s##%s", w.title, w.id)).IsOpen(&w.open).Size(TerminalWindowWidth, TerminalWindowHeight).Layout(
+		giu.Row(
+			giu.Button("Copy screen").OnClick(w.copyScreen),
+			giu.Button("Paste").OnClick(w.paste),
+			giu.Custom(func() {
+				// typed characters are captured by a tiny input widget that keeps the keyboard focus
+				focused := giu.IsWindowFocused(giu.FocusedFlagsRootAndChildWindows)
+				if focused && !imgui.IsAnyItemActive() {
+					giu.SetKeyboardFocusHere()
+				}
+				giu.InputText(&w.input).Label("##input" + w.id).Size(1).
+					Flags(giu.InputTextFlagsCallbackCharFilter).
+					Callback(func(data imgui.InputTextCallbackData) int32 {
+						w.send(string(data.EventChar()))
+						return 1
+					}).Build()
+				if focused {
+					w.handleKeys()
+				}
+			}),
+			giu.Label(status),
+		),
+		giu.Child().Border(false).Flags(giu.WindowFlagsNoScrollbar|giu.WindowFlagsNoScrollWithMouse).Layout(
+			giu.Custom(func() {
+				width, height := giu.GetAvailableRegion()
+				charWidth, charHeight := giu.CalcTextSize("M")
+				w.resize(int(height/charHeight), int(width/charWidth))
+				w.drawScreen(charWidth, charHeight)
+			}),
+		),
+	)
+}
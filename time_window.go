@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"github.com/AllenDang/giu"
+	"github.com/AllenDang/imgui-go"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	MinTimeRange = 30 * time.Second
+	MaxTimeRange = 7 * 24 * time.Hour
+
+	// TimeRangeZoomFactor is applied to the time range per step of the mouse wheel
+	TimeRangeZoomFactor = 1.25
+)
+
+var (
+	TimeRangePresets = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour, 6 * time.Hour}
+	TimeIntervals    = []float64{
+		10, 30, time.Minute.Seconds(), 2 * time.Minute.Seconds(), 5 * time.Minute.Seconds(), 10 * time.Minute.Seconds(),
+		15 * time.Minute.Seconds(), 30 * time.Minute.Seconds(), time.Hour.Seconds(), 2 * time.Hour.Seconds(),
+		6 * time.Hour.Seconds(), 12 * time.Hour.Seconds(), 24 * time.Hour.Seconds(),
+	}
+)
+
+// timeWindow returns the time window shown in history plots
+func (a *App) timeWindow() (float64, float64) {
+	until := a.now().Add(-a.timeRangeEnd)
+	return float64(until.Add(-a.timeRange).Unix()), float64(until.Unix())
+}
+
+// setTimeRange shows the given range in history plots, ending now
+func (a *App) setTimeRange(timeRange time.Duration) {
+	a.timeRange = min(MaxTimeRange, max(MinTimeRange, timeRange))
+	a.timeRangeEnd = 0
+}
+
+// zoomTimeWindow zooms in for positive steps and zooms out for negative steps, keeping the end of window
+func (a *App) zoomTimeWindow(steps float32) {
+	a.timeRange = time.Duration(float64(a.timeRange) * math.Pow(TimeRangeZoomFactor, -float64(steps)))
+	a.timeRange = min(MaxTimeRange, max(MinTimeRange, a.timeRange))
+}
+
+// panTimeWindow moves the window to the past for positive offsets, it will not move into the future
+func (a *App) panTimeWindow(offset time.Duration) {
+	a.timeRangeEnd = min(MaxTimeRange, max(0, a.timeRangeEnd+offset))
+}
+
+// timeWindowControl zooms the time window by mouse wheel while Ctrl is held and the previous item is hovered,
+// the wheel scrolls the window otherwise. Dragging the item pans the time window if pan is set.
+func (a *App) timeWindowControl(pan bool) giu.Widget {
+	return giu.Custom(func() {
+		if !giu.IsItemHovered() {
+			return
+		}
+		io := imgui.CurrentIO()
+		ctrl := giu.IsKeyDown(giu.KeyLeftControl) || giu.IsKeyDown(giu.KeyRightControl)
+		if wheel := io.GetMouseWheelDelta(); wheel != 0 && ctrl {
+			a.zoomTimeWindow(wheel)
+		}
+		if pan && giu.IsMouseDown(giu.MouseButtonLeft) {
+			width := imgui.GetItemRectSize().X
+			if delta := io.GetMouseDelta(); delta.X != 0 && width > 0 {
+				a.panTimeWindow(time.Duration(float64(delta.X) / float64(width) * float64(a.timeRange)))
+			}
+		}
+	})
+}
+
+// timeRangeMenu lets the user select the time window of history plots
+func (a *App) timeRangeMenu() giu.Widget {
+	return giu.Menu("Time range").Layout(
+		giu.Custom(func() {
+			for _, preset := range TimeRangePresets {
+				timeRange := preset
+				giu.MenuItem(formatTimeRange(timeRange)).Selected(a.timeRange == timeRange).OnClick(func() {
+					a.setTimeRange(timeRange)
+				}).Build()
+			}
+		}),
+		giu.MenuItem("Custom...").OnClick(func() {
+			a.customTimeRange = formatTimeRange(a.timeRange)
+			a.customTimeRangeError = ""
+			a.customTimeRangePopup.Open()
+		}),
+		giu.Separator(),
+		giu.MenuItem("Follow now").Enabled(a.timeRangeEnd > 0).OnClick(func() {
+			a.timeRangeEnd = 0
+		}),
+	)
+}
+
+// customTimeRangeLayout lets the user enter a time range like "90m" or "2h30m"
+func (a *App) customTimeRangeLayout() giu.Layout {
+	return giu.Layout{
+		giu.Label("Time range, e.g. 90s, 20m or 2h30m"),
+		giu.InputText(&a.customTimeRange),
+		giu.Condition(len(a.customTimeRangeError) > 0, giu.Layout{giu.Label(a.customTimeRangeError)}, nil),
+		giu.Row(
+			giu.Button("Apply").OnClick(func() {
+				timeRange, err := time.ParseDuration(a.customTimeRange)
+				if err != nil {
+					a.customTimeRangeError = fmt.Sprintf("Invalid time range: %v", err)
+					return
+				}
+				if timeRange < MinTimeRange || timeRange > MaxTimeRange {
+					a.customTimeRangeError = fmt.Sprintf("Time range must be within %s and %s", MinTimeRange, MaxTimeRange)
+					return
+				}
+				a.setTimeRange(timeRange)
+				a.customTimeRangePopup.Close()
+			}),
+			giu.Button("Cancel").OnClick(func() {
+				a.customTimeRangePopup.Close()
+			}),
+		),
+	}
+}
+
+// formatTimeRange returns the time range as short text, e.g. "15m" or "6h"
+func formatTimeRange(timeRange time.Duration) string {
+	text := timeRange.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// buildTimeTicker returns ticks of the time axis for given time window, the interval adapts to the window
+func buildTimeTicker(minXAxis float64, maxXAxis float64) []giu.PlotTicker {
+	interval := buildPlotInterval(minXAxis, maxXAxis, TimeIntervals)
+	return buildPlotTicker(minXAxis, maxXAxis, interval, func(value float64) string {
+		t := time.Unix(int64(value), 0)
+		h, m, s := t.Clock()
+		switch {
+		case interval < time.Minute.Seconds():
+			return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+		case maxXAxis-minXAxis > 24*time.Hour.Seconds():
+			return fmt.Sprintf("%02d.%02d %02d:%02d", t.Day(), t.Month(), h, m)
+		default:
+			return fmt.Sprintf("%02d:%02d", h, m)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/AllenDang/giu"
 	"github.com/inhies/go-bytesize"
@@ -20,12 +21,21 @@ const (
 	MByte = KByte * 1024
 	GByte = MByte * 1024
 
+	// TombstoneAlpha greys out cards of containers that are not running
+	TombstoneAlpha = 0.5
+)
+
+// built-in defaults of tunables of the UI, see Config
+var (
 	RecentDuration = time.Duration(5) * time.Minute
 
-	IconSize = 24
+	IconSize float32 = 24
 
 	TooltipWidth  = 300
 	TooltipHeight = 200
+
+	WindowWidth  = 600
+	WindowHeight = 600
 )
 
 type ContainerSortMode int32
@@ -45,6 +55,8 @@ var (
 	CpuIntervals         = []float64{1, 5, 10, 25, 50, 100, 200}
 	CpuBarColor          = color.RGBA{G: 255, A: 255}
 	CpuThrottledBarColor = color.RGBA{R: 255, G: 160, A: 255}
+
+	PIDsIntervals = []float64{1, 5, 10, 50, 100, 500, 1000}
 )
 
 // buildPlotTicker returns plot tickers derived from given min, max & interval
@@ -77,11 +89,33 @@ func buildPlotInterval(min, max float64, intervals []float64) float64 {
 	return interval
 }
 
+// HostData is the connection state of a followed host
+type HostData struct {
+	Name      string
+	Connected bool
+}
+
+// DaemonData is a container runtime selectable in the daemon menu
+type DaemonData struct {
+	Name string
+	Host string
+}
+
 type App struct {
-	containerDataMutex  sync.Mutex
-	containerData       []ContainerData
-	containerSortMode   ContainerSortMode
-	containerIdSelected string
+	containerDataMutex   sync.Mutex
+	containerData        []ContainerData
+	containerVisible     []ContainerData
+	containerSortMode    ContainerSortMode
+	containerIdSelected  string
+	containerIdDetail    string // container shown in the detail view instead of the grid
+	containerGroupByHost bool
+	containerShowExited  bool // show tombstones of containers that are not running
+
+	hostData   []HostData
+	hostFilter string
+
+	daemonData     []DaemonData
+	daemonSelected string
 
 	wnd *giu.MasterWindow
 
@@ -91,23 +125,77 @@ type App struct {
 	containerEnvVarsPopup *PopupModal
 	containerEnvVars      map[string]string
 
+	logViewers   []*LogViewer
+	terminals    []*TerminalWindow
+	inspectors   []*InspectViewer
+	windowNumber int // number of the last opened viewer window, makes ids of windows unique
+
+	alerts     []Alert // newest alert first
+	alertsOpen bool
+
+	processList      *ProcessList // processes of the container shown in the detail view
+	processListShown bool         // process list got rendered in the current frame
+
+	timeRange            time.Duration // duration of time window of history plots
+	timeRangeEnd         time.Duration // offset of the end of time window into the past
+	customTimeRangePopup *PopupModal
+	customTimeRange      string
+	customTimeRangeError string
+
+	historyExportPopup       *PopupModal
+	historyExportId          string // id of container whose history gets exported
+	historyExportProject     bool   // export all containers of the compose project of the container
+	historyExportFormat      string
+	historyExportDir         string   // directory of the export file, browsed in the dialog
+	historyExportSubDirs     []string // sub directories of historyExportDir
+	historyExportFile        string
+	historyExportDefaultFile string
+	historyExportMutex       sync.Mutex // guards the state of the export running in the background
+	historyExporting         bool
+	historyExportSaved       bool // the export succeeded, the dialog gets closed
+	historyExportError       string
+
+	now            func() time.Time // current time of the container data
+	replayClock    *ReplayClock     // clock of the replayed recording, nil if not replaying
+	replayPosition int32            // position of the seek slider in seconds since start of the recording
+	replaySeeking  bool             // seek slider got moved but is not released yet
+
 	healthyTexture   *giu.Texture
 	unhealthyTexture *giu.Texture
 	unknownTexture   *giu.Texture
 	restartTexture   *giu.Texture
 	stopTexture      *giu.Texture
+	startTexture     *giu.Texture
+	pauseTexture     *giu.Texture
+	killTexture      *giu.Texture
 
+	startContainer   func(id string)
 	stopContainer    func(id string)
 	restartContainer func(id string)
+	pauseContainer   func(id string)
+	unpauseContainer func(id string)
+	killContainer    func(id string, signal string)
+	followLogs       func(ctx context.Context, id string, tail string, line func(LogLine)) error
+	execContainer    func(ctx context.Context, id string, rows int, cols int) (*ExecSession, error)
+	listProcesses    func(ctx context.Context, id string) ([]Process, error)
+	fetchInspect     func(ctx context.Context, id string) ([]byte, error)
+	acknowledgeAlert func(id int)
+	signalProcess    func(id string, pid int, signal string)
+	selectDaemon     func(name string)
+	reloadDaemons    func()
 }
 
 func NewApp() *App {
-	app := &App{containerSortMode: ContainerSortByName}
-	app.wnd = giu.NewMasterWindow("Container HUD", 600, 600, 0)
+	app := &App{containerSortMode: ContainerSortByName, containerShowExited: true}
+	app.wnd = giu.NewMasterWindow("Container HUD", tunables().WindowWidth, tunables().WindowHeight, 0)
 	app.aboutPopup = NewPopupModal("About")
 	app.containerIdSelected = ""
 	app.containerEnvVars = make(map[string]string, 0)
 	app.containerEnvVarsPopup = NewPopupModal("Environment Variables")
+	app.timeRange = tunables().RecentDuration
+	app.customTimeRangePopup = NewPopupModal("Custom Time Range")
+	app.historyExportPopup = NewPopupModal("Export History")
+	app.now = time.Now
 	app.buildTextures()
 	return app
 }
@@ -116,6 +204,12 @@ func (a *App) BuildInfo(info string) {
 	a.buildInfo = info
 }
 
+// Replay shows the controls of the replay clock, the time of the clock is taken as current time
+func (a *App) Replay(clock *ReplayClock) {
+	a.replayClock = clock
+	a.now = clock.Now
+}
+
 func (a *App) buildTextures() {
 	image, _ := png.Decode(bytes.NewReader(heartHealthyIconData))
 	giu.EnqueueNewTextureFromRgba(image, func(tex *giu.Texture) {
@@ -141,6 +235,26 @@ func (a *App) buildTextures() {
 	giu.EnqueueNewTextureFromRgba(image, func(tex *giu.Texture) {
 		a.stopTexture = tex
 	})
+
+	image, _ = png.Decode(bytes.NewReader(startIconData))
+	giu.EnqueueNewTextureFromRgba(image, func(tex *giu.Texture) {
+		a.startTexture = tex
+	})
+
+	image, _ = png.Decode(bytes.NewReader(pauseIconData))
+	giu.EnqueueNewTextureFromRgba(image, func(tex *giu.Texture) {
+		a.pauseTexture = tex
+	})
+
+	image, _ = png.Decode(bytes.NewReader(killIconData))
+	giu.EnqueueNewTextureFromRgba(image, func(tex *giu.Texture) {
+		a.killTexture = tex
+	})
+}
+
+func (a *App) OnStartContainer(startContainer func(id string)) *App {
+	a.startContainer = startContainer
+	return a
 }
 
 func (a *App) OnStopContainer(stopContainer func(id string)) *App {
@@ -153,19 +267,96 @@ func (a *App) OnRestartContainer(restartContainer func(id string)) *App {
 	return a
 }
 
-func (a *App) ContainerData(data []ContainerData) {
+func (a *App) OnPauseContainer(pauseContainer func(id string)) *App {
+	a.pauseContainer = pauseContainer
+	return a
+}
+
+func (a *App) OnUnpauseContainer(unpauseContainer func(id string)) *App {
+	a.unpauseContainer = unpauseContainer
+	return a
+}
+
+func (a *App) OnKillContainer(killContainer func(id string, signal string)) *App {
+	a.killContainer = killContainer
+	return a
+}
+
+func (a *App) OnFollowLogs(followLogs func(ctx context.Context, id string, tail string, line func(LogLine)) error) *App {
+	a.followLogs = followLogs
+	return a
+}
+
+func (a *App) OnExecContainer(execContainer func(ctx context.Context, id string, rows int, cols int) (*ExecSession, error)) *App {
+	a.execContainer = execContainer
+	return a
+}
+
+func (a *App) OnListProcesses(listProcesses func(ctx context.Context, id string) ([]Process, error)) *App {
+	a.listProcesses = listProcesses
+	return a
+}
+
+func (a *App) OnFetchInspect(fetchInspect func(ctx context.Context, id string) ([]byte, error)) *App {
+	a.fetchInspect = fetchInspect
+	return a
+}
+
+func (a *App) OnAcknowledgeAlert(acknowledgeAlert func(id int)) *App {
+	a.acknowledgeAlert = acknowledgeAlert
+	return a
+}
+
+func (a *App) OnSignalProcess(signalProcess func(id string, pid int, signal string)) *App {
+	a.signalProcess = signalProcess
+	return a
+}
+
+func (a *App) OnSelectDaemon(selectDaemon func(name string)) *App {
+	a.selectDaemon = selectDaemon
+	return a
+}
+
+func (a *App) OnReloadDaemons(reloadDaemons func()) *App {
+	a.reloadDaemons = reloadDaemons
+	return a
+}
+
+// Daemons sets the daemons shown in the daemon menu and the currently selected one
+func (a *App) Daemons(daemons []DaemonData, selected string) {
+	a.containerDataMutex.Lock()
+	defer a.containerDataMutex.Unlock()
+
+	a.daemonData = daemons
+	a.daemonSelected = selected
+
+	giu.Update()
+}
+
+func (a *App) ContainerData(data []ContainerData, hosts []HostData) {
 	a.containerDataMutex.Lock()
 	defer a.containerDataMutex.Unlock()
 
 	a.containerData = data
+	a.hostData = hosts
+
+	giu.Update()
+}
+
+// AlertData sets the alerts to show, newest alert first
+func (a *App) AlertData(alerts []Alert) {
+	a.containerDataMutex.Lock()
+	defer a.containerDataMutex.Unlock()
+
+	a.alerts = alerts
 
 	giu.Update()
 }
 
 func (a *App) setContainerSelectedByIdx(idx int) {
 	selectedId := ""
-	if idx >= -1 && idx < len(a.containerData) {
-		selectedId = a.containerData[idx].ID
+	if idx >= 0 && idx < len(a.containerVisible) {
+		selectedId = a.containerVisible[idx].ID
 	}
 	if selectedId != a.containerIdSelected {
 		a.containerIdSelected = selectedId
@@ -186,6 +377,33 @@ func (a *App) getContainerByIdx(id string) int {
 	return -1
 }
 
+func (a *App) getVisibleContainerByIdx(id string) int {
+	for i := range a.containerVisible {
+		if a.containerVisible[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterContainerData returns the containers of the selected host or of all hosts, exited ones only if shown
+func (a *App) filterContainerData() []ContainerData {
+	if len(a.hostFilter) == 0 && a.containerShowExited {
+		return a.containerData
+	}
+	visible := make([]ContainerData, 0, len(a.containerData))
+	for _, data := range a.containerData {
+		if len(a.hostFilter) > 0 && data.Host != a.hostFilter {
+			continue
+		}
+		if !a.containerShowExited && data.State.IsTombstone() {
+			continue
+		}
+		visible = append(visible, data)
+	}
+	return visible
+}
+
 func (a *App) showContainerEnvVars(containerId string) {
 	idx := a.getContainerByIdx(containerId)
 	if idx >= 0 && idx < len(a.containerData) {
@@ -198,8 +416,169 @@ func (a *App) showContainerEnvVars(containerId string) {
 	}
 }
 
+// copyableVariables lists given variables sorted by name, a click copies the variable to clipboard
+func copyableVariables(kind string, vars map[string]string) giu.Widget {
+	return giu.Custom(func() {
+		names := make([]string, 0, len(vars))
+		for n := range vars {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			name := n
+			variable := fmt.Sprintf("%s=%s", name, vars[name])
+			giu.Selectable(variable).OnClick(
+				func() {
+					fmt.Printf("Copied %s %s to clipboard\n", kind, name)
+					clipboard.Write(clipboard.FmtText, []byte(variable))
+				}).Build()
+		}
+	})
+}
+
+// nextWindowId returns a unique id of a new viewer window of given container or project
+func (a *App) nextWindowId(id string) string {
+	a.windowNumber++
+	return fmt.Sprintf("%s-%d", id, a.windowNumber)
+}
+
+// showContainerLogs opens a log viewer streaming the logs of the container
+func (a *App) showContainerLogs(containerId string) {
+	idx := a.getContainerByIdx(containerId)
+	if idx < 0 {
+		return
+	}
+	data := a.containerData[idx]
+	ctx, cancel := context.WithCancel(context.Background())
+	viewer := NewLogViewer(a.nextWindowId(data.ID), fmt.Sprintf("Logs of %s", data.AlternativeName), cancel)
+	a.logViewers = append(a.logViewers, viewer)
+	go func() {
+		err := a.followLogs(ctx, data.ID, LogTailLines, viewer.Append)
+		if ctx.Err() == nil {
+			viewer.End(err)
+		}
+	}()
+}
+
+// IsRunningContainerSelected returns true if the selected container is running
+func (a *App) IsRunningContainerSelected() bool {
+	idx := a.getContainerByIdx(a.containerIdSelected)
+	return idx >= 0 && a.containerData[idx].State == ContainerRunning
+}
+
+// IsComposeProjectSelected returns true if the selected container belongs to a compose project
+func (a *App) IsComposeProjectSelected() bool {
+	idx := a.getContainerByIdx(a.containerIdSelected)
+	return idx >= 0 && len(a.containerData[idx].DockerComposeProject) > 0
+}
+
+// showComposeProjectLogs opens a log viewer merging the logs of all containers of the compose project of given container
+func (a *App) showComposeProjectLogs(containerId string) {
+	idx := a.getContainerByIdx(containerId)
+	if idx < 0 || len(a.containerData[idx].DockerComposeProject) == 0 {
+		return
+	}
+	project, host := a.containerData[idx].DockerComposeProject, a.containerData[idx].Host
+	var viewer *LogViewer
+	logs := NewProjectLogs(host, project, a.followLogs,
+		func(line LogLine) {
+			viewer.Append(line)
+		},
+		func(id string, err error) {
+			fmt.Printf("Failed to follow logs of container %s of compose project %s: %v\n", id, project, err)
+		})
+	viewer = NewLogViewer(a.nextWindowId(host+"/"+project), fmt.Sprintf("Logs of compose project %s", project), func() {
+		removeNotificationChannel("logs", logs)
+	})
+	a.logViewers = append(a.logViewers, viewer)
+
+	// follow containers of the project started later on too
+	addNotificationChannel("logs", logs)
+	for _, data := range a.containerData {
+		if data.DockerComposeProject == project && data.Host == host {
+			logs.Follow(data.ID, data.AlternativeName)
+		}
+	}
+}
+
+// showContainerShell opens a terminal window with an interactive shell executed in the container
+func (a *App) showContainerShell(containerId string) {
+	idx := a.getContainerByIdx(containerId)
+	if idx < 0 || a.containerData[idx].State != ContainerRunning {
+		return
+	}
+	data := a.containerData[idx]
+	ctx, cancel := context.WithCancel(context.Background())
+	terminal := NewTerminalWindow(a.nextWindowId(data.ID), fmt.Sprintf("Shell in %s", data.AlternativeName), cancel)
+	a.terminals = append(a.terminals, terminal)
+	go func() {
+		rows, cols := terminal.terminal.Size()
+		session, err := a.execContainer(ctx, data.ID, rows, cols)
+		if err != nil {
+			terminal.End(err)
+			return
+		}
+		terminal.Attach(session)
+	}()
+}
+
+// showContainerInspect opens a window showing the inspect result of the container as tree
+func (a *App) showContainerInspect(containerId string) {
+	idx := a.getContainerByIdx(containerId)
+	if idx < 0 {
+		return
+	}
+	data := a.containerData[idx]
+	inspector := NewInspectViewer(a.nextWindowId(data.ID), data.ID, fmt.Sprintf("Inspect of %s", data.AlternativeName), a.fetchInspect)
+	a.inspectors = append(a.inspectors, inspector)
+	go inspector.Refresh()
+}
+
+// renderInspectors builds the windows of open inspect viewers and drops closed ones
+func (a *App) renderInspectors() {
+	open := a.inspectors[:0]
+	for _, inspector := range a.inspectors {
+		inspector.Render()
+		if inspector.IsOpen() {
+			open = append(open, inspector)
+		}
+	}
+	a.inspectors = open
+}
+
+// renderTerminals builds the windows of open terminals and drops closed ones
+func (a *App) renderTerminals() {
+	open := a.terminals[:0]
+	for _, terminal := range a.terminals {
+		terminal.Render()
+		if terminal.IsOpen() {
+			open = append(open, terminal)
+		} else {
+			terminal.Close()
+		}
+	}
+	a.terminals = open
+}
+
+// renderLogViewers builds the windows of open log viewers and drops closed ones
+func (a *App) renderLogViewers() {
+	open := a.logViewers[:0]
+	for _, viewer := range a.logViewers {
+		viewer.Render()
+		if viewer.IsOpen() {
+			open = append(open, viewer)
+		} else {
+			viewer.Close()
+		}
+	}
+	a.logViewers = open
+}
+
 func (a *App) sortContainerData() {
 	sort.SliceStable(a.containerData, func(i int, j int) bool {
+		if a.containerGroupByHost && a.containerData[i].Host != a.containerData[j].Host {
+			return a.containerData[i].Host < a.containerData[j].Host
+		}
 		switch a.containerSortMode {
 		case ContainerSortByCreated:
 			if a.containerData[i].Created < a.containerData[j].Created {
@@ -230,31 +609,30 @@ func (a *App) Render() {
 	a.containerDataMutex.Lock()
 	defer a.containerDataMutex.Unlock()
 
-	nofContainer := len(a.containerData)
+	a.sortContainerData()
+	a.containerVisible = a.filterContainerData()
+
+	nofContainer := len(a.containerVisible)
+	nofRunning := 0
 	totalCpuPercent := float64(0)
 	totalMemory := uint64(0)
-	for _, data := range a.containerData {
+	for _, data := range a.containerVisible {
+		if data.State.IsTombstone() {
+			continue
+		}
+		nofRunning++
 		totalCpuPercent += data.CpuPercent
 		totalMemory += data.Memory
 	}
 
-	w, h := app.wnd.GetSize()
-	bestColumns := 0
-	bestRows := 0
-	bestFit := math.MaxFloat64
-	targetAspectRatio := 1.
-	for columns := 1; columns <= nofContainer; columns++ {
-		rows := int(math.Ceil(float64(nofContainer) / float64(columns)))
-		fit := math.Abs(targetAspectRatio - (float64(w)/float64(columns))/(float64(h)/float64(rows)))
-		if fit < bestFit {
-			bestColumns = columns
-			bestRows = rows
-			bestFit = fit
-		}
+	var content giu.Widget
+	if detailIdx := a.getContainerByIdx(a.containerIdDetail); detailIdx >= 0 {
+		content = a.renderContainerDetail(a.containerData[detailIdx])
+	} else {
+		a.containerIdDetail = ""
+		content = a.renderContainerGrid(nofContainer, nofRunning, totalCpuPercent, totalMemory)
 	}
 
-	a.sortContainerData()
-
 	giu.SingleWindowWithMenuBar().Layout(
 		app.aboutPopup.Layout(
 			giu.Label(a.buildInfo),
@@ -263,26 +641,18 @@ func (a *App) Render() {
 
 		app.containerEnvVarsPopup.Layout(
 			giu.Label("Click variable to copy it to clipboard..."),
-			giu.Custom(func() {
-				envVarNames := make([]string, 0, len(app.containerEnvVars))
-				for n, _ := range app.containerEnvVars {
-					envVarNames = append(envVarNames, n)
-				}
-				sort.SliceStable(envVarNames, func(i int, j int) bool {
-					return envVarNames[i] < envVarNames[j]
-				})
-				for _, n := range envVarNames {
-					env := fmt.Sprintf("%s=%s", n, app.containerEnvVars[n])
-					giu.Selectable(env).OnClick(
-						func() {
-							fmt.Printf("Copied envvar %s to clipboard\n", n)
-							clipboard.Write(clipboard.FmtText, []byte(env))
-						}).Build()
-				}
-			}),
+			copyableVariables("envvar", app.containerEnvVars),
 		),
 		giu.PrepareMsgbox(),
 
+		app.customTimeRangePopup.Layout(
+			a.customTimeRangeLayout(),
+		),
+
+		app.historyExportPopup.Layout(
+			a.historyExportLayout(),
+		),
+
 		giu.MenuBar().Layout(
 			giu.Menu("View").Layout(
 				giu.MenuItem("Sort containers by name").Selected(a.containerSortMode == ContainerSortByName).OnClick(func() {
@@ -291,11 +661,74 @@ func (a *App) Render() {
 				giu.MenuItem("Sort containers by creation time").Selected(a.containerSortMode == ContainerSortByCreated).OnClick(func() {
 					a.containerSortMode = ContainerSortByCreated
 				}),
+				giu.Separator(),
+				a.timeRangeMenu(),
+				giu.Separator(),
+				giu.MenuItem("Group containers by host").Selected(a.containerGroupByHost).OnClick(func() {
+					a.containerGroupByHost = !a.containerGroupByHost
+				}),
+				giu.MenuItem("Show exited containers").Selected(a.containerShowExited).OnClick(func() {
+					a.containerShowExited = !a.containerShowExited
+				}),
+				giu.Menu("Show host").Layout(
+					giu.MenuItem("All hosts").Selected(len(a.hostFilter) == 0).OnClick(func() {
+						a.hostFilter = ""
+					}),
+					giu.Custom(func() {
+						for _, host := range a.hostData {
+							name := host.Name
+							giu.MenuItem(name).Selected(a.hostFilter == name).OnClick(func() {
+								a.hostFilter = name
+							}).Build()
+						}
+					}),
+				),
+			),
+			giu.Menu("Daemon").Layout(
+				giu.Custom(func() {
+					for _, daemon := range a.daemonData {
+						name := daemon.Name
+						giu.MenuItem(name).Selected(a.daemonSelected == name).OnClick(func() {
+							go a.selectDaemon(name)
+						}).Build()
+						giu.Tooltip(daemon.Host).Build()
+					}
+				}),
+				giu.Separator(),
+				giu.MenuItem("Reload daemons").OnClick(func() {
+					go a.reloadDaemons()
+				}),
 			),
 			giu.Menu("Container").Enabled(a.IsContainerSelected()).Layout(
+				giu.MenuItem("Show details").OnClick(func() {
+					a.containerIdDetail = a.containerIdSelected
+				}),
 				giu.MenuItem("Show envvars").OnClick(func() {
 					a.showContainerEnvVars(a.containerIdSelected)
 				}),
+				giu.MenuItem("Show inspect").OnClick(func() {
+					a.showContainerInspect(a.containerIdSelected)
+				}),
+				giu.MenuItem("Show logs").OnClick(func() {
+					a.showContainerLogs(a.containerIdSelected)
+				}),
+				giu.MenuItem("Show compose project logs").Enabled(a.IsComposeProjectSelected()).OnClick(func() {
+					a.showComposeProjectLogs(a.containerIdSelected)
+				}),
+				giu.MenuItem("Open shell").Enabled(a.IsRunningContainerSelected()).OnClick(func() {
+					a.showContainerShell(a.containerIdSelected)
+				}),
+				giu.MenuItem("Export history...").OnClick(func() {
+					a.showHistoryExport(a.containerIdSelected)
+				}),
+			),
+			giu.Menu(fmt.Sprintf("Alerts (%d)##alerts", a.unacknowledgedAlerts())).Layout(
+				giu.MenuItem("Show alerts").OnClick(func() {
+					a.alertsOpen = true
+				}),
+				giu.MenuItem("Acknowledge all").OnClick(func() {
+					a.acknowledgeAllAlerts()
+				}),
 			),
 			giu.Menu("Help").Layout(
 				giu.MenuItem("About").OnClick(func() {
@@ -303,13 +736,49 @@ func (a *App) Render() {
 				}),
 			),
 		),
+		content,
+	)
+
+	// stop refreshing processes that are not shown, e.g. of a collapsed process list
+	if a.processList != nil && !a.processListShown {
+		a.processList.Stop()
+		a.processList = nil
+	}
+	a.processListShown = false
+
+	a.renderLogViewers()
+	a.renderTerminals()
+	a.renderInspectors()
+	a.renderAlertWindow()
+	a.renderReplayWindow()
+}
+
+// renderContainerGrid shows the totals and a card per visible container
+func (a *App) renderContainerGrid(nofContainer int, nofRunning int, totalCpuPercent float64, totalMemory uint64) giu.Layout {
+	w, h := app.wnd.GetSize()
+	bestColumns := 0
+	bestRows := 0
+	bestFit := math.MaxFloat64
+	targetAspectRatio := 1.
+	for columns := 1; columns <= nofContainer; columns++ {
+		rows := int(math.Ceil(float64(nofContainer) / float64(columns)))
+		fit := math.Abs(targetAspectRatio - (float64(w)/float64(columns))/(float64(h)/float64(rows)))
+		if fit < bestFit {
+			bestColumns = columns
+			bestRows = rows
+			bestFit = fit
+		}
+	}
+
+	return giu.Layout{
 		giu.Condition(
 			nofContainer > 0,
 			giu.Layout{
 				giu.Row(
 					giu.Label(
-						fmt.Sprintf("%d containers ( %5.1f%% CPU, %s Mem )",
+						fmt.Sprintf("%d containers, %d running ( %5.1f%% CPU, %s Mem )",
 							nofContainer,
+							nofRunning,
 							totalCpuPercent,
 							bytesize.New(float64(totalMemory)).String(),
 						),
@@ -317,25 +786,79 @@ func (a *App) Render() {
 				),
 			},
 			giu.Layout{
-				giu.Label("No containers are running"),
+				giu.Label("No containers found"),
 			},
 		),
-		GridBuilder[ContainerData]("containers", bestColumns, bestRows, a.containerData, a.getContainerByIdx(a.containerIdSelected),
+		a.renderHostTotals(),
+		GridBuilder[ContainerData]("containers", bestColumns, bestRows, a.containerVisible, a.getVisibleContainerByIdx(a.containerIdSelected),
 			a.setContainerSelectedByIdx,
+			func(idx int) {
+				a.setContainerSelectedByIdx(idx)
+				a.containerIdDetail = a.containerIdSelected
+			},
 			func(_ int, selected bool, data ContainerData) giu.Widget {
 				return a.renderContainerData(selected, data)
 			}),
-	)
+	}
+}
+
+// renderHostTotals shows the totals per host if more than one host is followed
+func (a *App) renderHostTotals() giu.Widget {
+	if len(a.hostData) < 2 {
+		return giu.Layout{}
+	}
+	var layout giu.Layout
+	for _, host := range a.hostData {
+		if len(a.hostFilter) > 0 && host.Name != a.hostFilter {
+			continue
+		}
+		if !host.Connected {
+			layout = append(layout, giu.Label(fmt.Sprintf("  %s: unreachable", host.Name)))
+			continue
+		}
+		nofContainer := 0
+		totalCpuPercent := float64(0)
+		totalMemory := uint64(0)
+		for _, data := range a.containerData {
+			if data.Host == host.Name && !data.State.IsTombstone() {
+				nofContainer++
+				totalCpuPercent += data.CpuPercent
+				totalMemory += data.Memory
+			}
+		}
+		layout = append(layout, giu.Label(
+			fmt.Sprintf("  %s: %d running containers ( %5.1f%% CPU, %s Mem )",
+				host.Name,
+				nofContainer,
+				totalCpuPercent,
+				bytesize.New(float64(totalMemory)).String(),
+			),
+		))
+	}
+	return layout
 }
 
 func (a *App) renderContainerData(selected bool, data ContainerData) giu.Widget {
-	minXAxis := float64(time.Now().Add(-RecentDuration).Unix())
-	maxXAxis := float64(time.Now().Unix())
+	minXAxis, maxXAxis := a.timeWindow()
+	tombstone := data.State.IsTombstone()
+	alpha := float32(1)
+	if tombstone {
+		alpha = TombstoneAlpha
+	}
 	return giu.Layout([]giu.Widget{
-		conditionalTexture(data.HealthStatus == UnknownHealth, a.unknownTexture, "Unknown container health status"),
-		conditionalTexture(data.HealthStatus == Unhealthy, a.unhealthyTexture, "Container is unhealthy"),
-		conditionalTexture(data.HealthStatus == Healthy, a.healthyTexture, "Container is healthy"),
-		giu.Custom(func() { giu.SameLine() }),
+		giu.Condition(!tombstone,
+			giu.Layout{
+				conditionalTexture(data.HealthStatus == UnknownHealth, a.unknownTexture, "Unknown container health status"),
+				conditionalTexture(data.HealthStatus == StartingHealth, a.unknownTexture, "Container health check is starting"),
+				conditionalTexture(data.HealthStatus == Unhealthy, a.unhealthyTexture, "Container is unhealthy"),
+				conditionalTexture(data.HealthStatus == Healthy, a.healthyTexture, "Container is healthy"),
+				giu.Custom(func() { giu.SameLine() }),
+			},
+			nil,
+		),
+		conditionalButton(data.State == ContainerExited || data.State == ContainerCreated, a.startTexture, "Start container", func() {
+			go a.startContainer(data.ID)
+		}),
 		conditionalButton(data.State == ContainerRunning, a.restartTexture, "Restart container", func() {
 			go a.restartContainer(data.ID)
 		}),
@@ -343,227 +866,284 @@ func (a *App) renderContainerData(selected bool, data ContainerData) giu.Widget
 		conditionalButton(data.State == ContainerRunning, a.stopTexture, "Stop container", func() {
 			go a.stopContainer(data.ID)
 		}),
+		giu.Custom(func() { giu.SameLine() }),
+		a.pauseButton(data),
+		giu.Custom(func() { giu.SameLine() }),
+		a.killButton(data),
 		giu.Dummy(0, 0),
-		ShortLabel(data.AlternativeName),
-		giu.ContextMenu().Layout(
-			giu.Label(fmt.Sprintf("Uptime %s", time.Since(time.Unix(data.Created, 0)).Round(time.Second))),
-			giu.Label(fmt.Sprintf("Image  %s", data.Image)),
-		),
-		ShortLabel(fmt.Sprintf("ID %s", data.ID[:12])),
-		giu.ContextMenu().Layout(
-			giu.Selectable("Copy to clipboard").OnClick(func() {
-				fmt.Printf("Copied ID %s to clipboard\n", data.ID[:12])
-				clipboard.Write(clipboard.FmtText, []byte(data.ID[:12]))
-			}),
-		),
-		giu.Column(
-			Bar().Label(
-				fmt.Sprintf("CPU  %0.1f%%, %d PIDs", data.CpuPercent, data.PIDs),
-			).Min(0).Value(data.CpuPercent).Max(CpuMaxPercent).Height(16).Foreground(CpuBarColor),
+		giu.Style().SetStyleFloat(giu.StyleVarAlpha, alpha).To(
+			ShortLabel(data.AlternativeName),
+			giu.ContextMenu().Layout(
+				giu.Label(fmt.Sprintf("Uptime %s", a.now().Sub(time.Unix(data.Created, 0)).Round(time.Second))),
+				giu.Label(fmt.Sprintf("Image  %s", data.Image)),
+				giu.Label(fmt.Sprintf("Host   %s", data.Host)),
+			),
+			ShortLabel(fmt.Sprintf("ID %s", data.ID[:12])),
+			giu.ContextMenu().Layout(
+				giu.Selectable("Copy to clipboard").OnClick(func() {
+					fmt.Printf("Copied ID %s to clipboard\n", data.ID[:12])
+					clipboard.Write(clipboard.FmtText, []byte(data.ID[:12]))
+				}),
+			),
+			giu.Condition(tombstone, giu.Layout{ShortLabel(exitSummary(data, a.now()))}, nil),
+			giu.Condition(data.State == ContainerPaused, giu.Layout{ShortLabel("Paused")}, nil),
+			giu.Column(
+				Bar().Label(
+					fmt.Sprintf("CPU  %0.1f%%, %d PIDs", data.CpuPercent, data.PIDs),
+				).Min(0).Value(data.CpuPercent).Max(CpuMaxPercent).Height(16).Foreground(color.RGBA(tunables().CpuBarColor)),
+				Bar().Label(
+					fmt.Sprintf("     %0.1f%% throttled", data.CpuThrottledPercent),
+				).Min(0).Value(data.CpuThrottledPercent).Max(100).Height(16).Foreground(color.RGBA(tunables().CpuThrottledBarColor)),
+			),
+			a.timeWindowControl(false),
+			cpuHistoryTooltip(data, minXAxis, maxXAxis),
 			Bar().Label(
-				fmt.Sprintf("     %0.1f%% throttled", data.CpuThrottledPercent),
-			).Min(0).Value(data.CpuThrottledPercent).Max(100).Height(16).Foreground(CpuThrottledBarColor),
+				fmt.Sprintf("Mem  %0.1f%% = %s", data.MemoryPercent, bytesize.New(float64(data.Memory))),
+			).Min(0).Value(float64(data.Memory)).Max(float64(data.MemoryLimit)).Height(16).Foreground(color.RGBA(tunables().MemBarColor)),
+			a.timeWindowControl(false),
+			memoryHistoryTooltip(data, minXAxis, maxXAxis),
+			giu.Label(fmt.Sprintf("Network RX %s\n        TX %s", bytesize.New(float64(data.NetworkRx)), bytesize.New(float64(data.NetworkTx)))),
+			a.timeWindowControl(false),
+			networkHistoryTooltip(data, minXAxis, maxXAxis),
 		),
-		cpuHistoryTooltip(data, minXAxis, maxXAxis),
-		Bar().Label(
-			fmt.Sprintf("Mem  %0.1f%% = %s", data.MemoryPercent, bytesize.New(float64(data.Memory))),
-		).Min(0).Value(float64(data.Memory)).Max(float64(data.MemoryLimit)).Height(16).Foreground(MemBarColor),
-		memoryHistoryTooltip(data, minXAxis, maxXAxis),
-		giu.Label(fmt.Sprintf("Network RX %s\n        TX %s", bytesize.New(float64(data.NetworkRx)), bytesize.New(float64(data.NetworkTx)))),
-		networkHistoryTooltip(data, minXAxis, maxXAxis),
 	})
 }
 
+// exitSummary describes why and when a container that is not running exited, e.g. "Exited (137) 5m ago, OOM killed"
+func exitSummary(data ContainerData, now time.Time) string {
+	switch data.State {
+	case ContainerCreated:
+		return "Created, never started"
+	case ContainerStarting:
+		return "Starting..."
+	}
+	summary := fmt.Sprintf("Exited (%d)", data.ExitCode)
+	if data.FinishedAt > 0 {
+		summary += fmt.Sprintf(" %s ago", now.Sub(time.Unix(data.FinishedAt, 0)).Round(time.Second))
+	}
+	if data.OOMKilled {
+		summary += ", OOM killed"
+	}
+	return summary
+}
+
+// pauseButton pauses a running container or unpauses a paused container
+func (a *App) pauseButton(data ContainerData) giu.Widget {
+	return giu.Layout{
+		conditionalButton(data.State == ContainerRunning, a.pauseTexture, "Pause container", func() {
+			go a.pauseContainer(data.ID)
+		}),
+		conditionalButton(data.State == ContainerPaused, a.startTexture, "Unpause container", func() {
+			go a.unpauseContainer(data.ID)
+		}),
+	}
+}
+
+// killButton opens a menu to choose the signal sent to a running or paused container
+func (a *App) killButton(data ContainerData) giu.Widget {
+	return conditionalButton(data.State == ContainerRunning || data.State == ContainerPaused, a.killTexture, "Send signal to container", nil,
+		giu.Custom(func() {
+			for _, signal := range KillSignals {
+				sig := signal
+				giu.Selectable(sig).OnClick(func() {
+					go a.killContainer(data.ID, sig)
+				}).Build()
+			}
+		}),
+	)
+}
+
 func conditionalTexture(show bool, texture *giu.Texture, tooltip string) giu.Widget {
 	return giu.Condition(
 		show,
 		giu.Layout{
-			giu.Image(texture).BorderCol(color.Transparent).Size(IconSize, IconSize),
+			giu.Image(texture).BorderCol(color.Transparent).Size(float32(tunables().IconSize), float32(tunables().IconSize)),
 			giu.Tooltip(tooltip),
 		},
 		nil,
 	)
 }
 
-func conditionalButton(show bool, texture *giu.Texture, tooltip string, onClick func()) giu.Widget {
-	return giu.Condition(
-		show,
-		giu.Layout{
-			giu.ImageButton(texture).FramePadding(0).BgColor(color.Transparent).Size(IconSize, IconSize).OnClick(onClick),
-			giu.Tooltip(tooltip),
-		},
-		nil,
+// conditionalButton shows an image button if show is true, clicking it opens a menu of given items if any
+func conditionalButton(show bool, texture *giu.Texture, tooltip string, onClick func(), menu ...giu.Widget) giu.Widget {
+	layout := giu.Layout{
+		giu.ImageButton(texture).FramePadding(0).BgColor(color.Transparent).Size(float32(tunables().IconSize), float32(tunables().IconSize)).OnClick(onClick),
+		giu.Tooltip(tooltip),
+	}
+	if len(menu) > 0 {
+		layout = append(layout, giu.ContextMenu().MouseButton(giu.MouseButtonLeft).Layout(menu...))
+	}
+	return giu.Condition(show, layout, nil)
+}
+
+// historyPlotSeries is a history shown as a line of a history plot
+type historyPlotSeries struct {
+	label   string
+	history *History
+}
+
+// historyPlot plots the series within given time window, the y axis fits the values within the window
+func historyPlot(title string, width int, height int, minXAxis float64, maxXAxis float64, series []historyPlotSeries, yIntervals []float64, yTickLabel func(float64) string) giu.Widget {
+	return giu.Custom(func() {
+		var (
+			xTicks   []giu.PlotTicker = nil
+			yTicks   []giu.PlotTicker = nil
+			yAxisMin                  = 0.
+			yAxisMax                  = 0.
+			yMin                      = math.MaxFloat64
+			yMax                      = -math.MaxFloat64
+			lines                     = make([]giu.PlotWidget, 0, len(series))
+		)
+		for _, s := range series {
+			x, y := s.history.GetXY(minXAxis, maxXAxis)
+			seriesMin, seriesMax := s.history.GetYMinMax(minXAxis, maxXAxis)
+			yMin = math.Min(yMin, seriesMin)
+			yMax = math.Max(yMax, seriesMax)
+			lines = append(lines, giu.PlotLineXY(s.label, x, y))
+		}
+		if yMin <= yMax {
+			xTicks = buildTimeTicker(minXAxis, maxXAxis)
+			yInterval := buildPlotInterval(yMin, yMax, yIntervals)
+			yTicks = buildPlotTicker(yMin, yMax, yInterval, yTickLabel)
+			yAxisMin = yTicks[0].Position
+			yAxisMax = yTicks[len(yTicks)-1].Position
+		}
+		flags := giu.PlotFlagsNone
+		if len(series) < 2 {
+			flags = giu.PlotFlagsNoLegend
+		}
+		giu.Plot(
+			title,
+		).Size(
+			width, height,
+		).Flags(
+			flags,
+		).AxisLimits(
+			minXAxis,
+			maxXAxis,
+			yAxisMin,
+			yAxisMax,
+			giu.ConditionAlways,
+		).Plots(
+			lines...,
+		).XAxeFlags(
+			giu.PlotAxisFlagsTime,
+		).XTicks(
+			xTicks, false,
+		).YTicks(
+			yTicks, false, 0,
+		).Build()
+	})
+}
+
+func formatPercentTick(value float64) string {
+	return fmt.Sprintf("%0.0f %%", value)
+}
+
+func formatBytesTick(value float64) string {
+	return bytesize.New(value).String()
+}
+
+func formatCountTick(value float64) string {
+	return fmt.Sprintf("%0.0f", value)
+}
+
+func cpuHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, cpuMax := data.CpuPercentHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf("CPU: avg %0.1f%%, max %0.1f%%,\navg throttled %0.1f%%",
+			data.CpuPercentHistory.GetYAvg(minXAxis, maxXAxis), cpuMax, data.CpuThrottledPercentHistory.GetYAvg(minXAxis, maxXAxis)),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"CPU", &data.CpuPercentHistory}, {"Throttled", &data.CpuThrottledPercentHistory}},
+		CpuIntervals, formatPercentTick,
+	)
+}
+
+func cpuOnlyHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, cpuMax := data.CpuPercentHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf("CPU: avg %0.1f%%, max %0.1f%%", data.CpuPercentHistory.GetYAvg(minXAxis, maxXAxis), cpuMax),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"CPU", &data.CpuPercentHistory}},
+		CpuIntervals, formatPercentTick,
+	)
+}
+
+func cpuThrottledHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, throttledMax := data.CpuThrottledPercentHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf("Throttled: avg %0.1f%%, max %0.1f%%", data.CpuThrottledPercentHistory.GetYAvg(minXAxis, maxXAxis), throttledMax),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"Throttled", &data.CpuThrottledPercentHistory}},
+		CpuIntervals, formatPercentTick,
+	)
+}
+
+func memoryHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, memMax := data.MemoryHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf("Mem: avg %s\n     max %s", bytesize.New(data.MemoryHistory.GetYAvg(minXAxis, maxXAxis)), bytesize.New(memMax)),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"Mem", &data.MemoryHistory}},
+		MemoryIntervals, formatBytesTick,
+	)
+}
+
+func networkHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, netRxMax := data.NetworkRxHistory.GetYMinMax(minXAxis, maxXAxis)
+	_, netTxMax := data.NetworkTxHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf(
+			"RX: avg %s, max %s\nTX: avg %s, max %s",
+			bytesize.New(data.NetworkRxHistory.GetYAvg(minXAxis, maxXAxis)), bytesize.New(netRxMax),
+			bytesize.New(data.NetworkTxHistory.GetYAvg(minXAxis, maxXAxis)), bytesize.New(netTxMax)),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"RX", &data.NetworkRxHistory}, {"TX", &data.NetworkTxHistory}},
+		MemoryIntervals, formatBytesTick,
+	)
+}
+
+func blockIOHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, readMax := data.BlockReadHistory.GetYMinMax(minXAxis, maxXAxis)
+	_, writeMax := data.BlockWriteHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf(
+			"Read: avg %s, max %s\nWrite: avg %s, max %s",
+			bytesize.New(data.BlockReadHistory.GetYAvg(minXAxis, maxXAxis)), bytesize.New(readMax),
+			bytesize.New(data.BlockWriteHistory.GetYAvg(minXAxis, maxXAxis)), bytesize.New(writeMax)),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"Read", &data.BlockReadHistory}, {"Write", &data.BlockWriteHistory}},
+		MemoryIntervals, formatBytesTick,
+	)
+}
+
+func pidsHistoryPlot(data ContainerData, minXAxis float64, maxXAxis float64, width int, height int) giu.Widget {
+	_, pidsMax := data.PIDsHistory.GetYMinMax(minXAxis, maxXAxis)
+	return historyPlot(
+		fmt.Sprintf("PIDs: avg %0.1f, max %0.0f", data.PIDsHistory.GetYAvg(minXAxis, maxXAxis), pidsMax),
+		width, height, minXAxis, maxXAxis,
+		[]historyPlotSeries{{"PIDs", &data.PIDsHistory}},
+		PIDsIntervals, formatCountTick,
 	)
 }
 
 func cpuHistoryTooltip(data ContainerData, minXAxis float64, maxXAxis float64) giu.Widget {
 	return giu.Tooltip("CPU History").Layout(
 		giu.Label(data.AlternativeName),
-		giu.Custom(func() {
-			var (
-				xTicks                                            []giu.PlotTicker = nil
-				yTicks                                            []giu.PlotTicker = nil
-				yAxisMin                                                           = 0.
-				yAxisMax                                                           = 0.
-				cpuX, cpuY                                                         = make([]float64, 0), make([]float64, 0)
-				cpuThrottledX, cpuThrottledY                                       = make([]float64, 0), make([]float64, 0)
-				cpuMin, cpuMax, cpuAvg                            float64
-				cpuThrottledMin, cpuThrottledMax, cpuThrottledAvg float64
-				totalMin, totalMax                                float64
-			)
-			if len(data.CpuPercentHistory.Samples) > 0 {
-				cpuX, cpuY = data.CpuPercentHistory.GetXY()
-				cpuMin, cpuMax = data.CpuPercentHistory.GetYMinMax(minXAxis, maxXAxis)
-				cpuAvg = data.CpuPercentHistory.GetYAvg(minXAxis, maxXAxis)
-				cpuThrottledX, cpuThrottledY = data.CpuThrottledPercentHistory.GetXY()
-				cpuThrottledMin, cpuThrottledMax = data.CpuThrottledPercentHistory.GetYMinMax(minXAxis, maxXAxis)
-				cpuThrottledAvg = data.CpuThrottledPercentHistory.GetYAvg(minXAxis, maxXAxis)
-				totalMin = math.Min(cpuMin, cpuThrottledMin)
-				totalMax = math.Max(cpuMax, cpuThrottledMax)
-				xTicks = buildPlotTicker(minXAxis, maxXAxis, 2*time.Minute.Seconds(), func(value float64) string {
-					h, m, _ := time.Unix(int64(value), 0).Clock()
-					return fmt.Sprintf("%02d:%02d", h, m)
-				})
-				yInterval := buildPlotInterval(totalMin, totalMax, CpuIntervals)
-				yTicks = buildPlotTicker(totalMin, totalMax, yInterval, func(value float64) string {
-					return fmt.Sprintf("%0.0f %%", value)
-				})
-				yAxisMin = yTicks[0].Position
-				yAxisMax = yTicks[len(yTicks)-1].Position
-			}
-			giu.Plot(
-				fmt.Sprintf("CPU: avg %0.1f%%, max %0.1f%%,\navg throttled %0.1f%%", cpuAvg, cpuMax, cpuThrottledAvg),
-			).Size(
-				TooltipWidth, TooltipHeight,
-			).AxisLimits(
-				minXAxis,
-				maxXAxis,
-				yAxisMin,
-				yAxisMax,
-				giu.ConditionAlways,
-			).Plots(
-				giu.PlotLineXY("CPU", cpuX, cpuY),
-				giu.PlotLineXY("Throttled", cpuThrottledX, cpuThrottledY),
-			).XTicks(
-				xTicks, false,
-			).YTicks(
-				yTicks, false, 0,
-			).XAxeFlags(
-				giu.PlotAxisFlagsTime,
-			).Build()
-		}),
+		cpuHistoryPlot(data, minXAxis, maxXAxis, tunables().TooltipWidth, tunables().TooltipHeight),
 	)
 }
 
 func memoryHistoryTooltip(data ContainerData, minXAxis float64, maxXAxis float64) giu.Widget {
 	return giu.Tooltip("Mem History").Layout(
 		giu.Label(data.AlternativeName),
-		giu.Custom(func() {
-			var (
-				xTicks                 []giu.PlotTicker = nil
-				yTicks                 []giu.PlotTicker = nil
-				yAxisMin                                = 0.
-				yAxisMax                                = 0.
-				memX, memY                              = make([]float64, 0), make([]float64, 0)
-				memMin, memMax, memAvg float64
-			)
-			if len(data.MemoryHistory.Samples) > 0 {
-				memX, memY = data.MemoryHistory.GetXY()
-				memMin, memMax = data.MemoryHistory.GetYMinMax(minXAxis, maxXAxis)
-				memAvg = data.MemoryHistory.GetYAvg(minXAxis, maxXAxis)
-				xTicks = buildPlotTicker(minXAxis, maxXAxis, 2*time.Minute.Seconds(), func(value float64) string {
-					h, m, _ := time.Unix(int64(value), 0).Clock()
-					return fmt.Sprintf("%02d:%02d", h, m)
-				})
-				yInterval := buildPlotInterval(memMin, memMax, MemoryIntervals)
-				yTicks = buildPlotTicker(memMin, memMax, yInterval, func(value float64) string {
-					return bytesize.New(value).String()
-				})
-				yAxisMin = yTicks[0].Position
-				yAxisMax = yTicks[len(yTicks)-1].Position
-			}
-			giu.Plot(
-				fmt.Sprintf("Mem: avg %s\n     max %s", bytesize.New(memAvg).String(), bytesize.New(memMax).String()),
-			).Size(
-				TooltipWidth, TooltipHeight,
-			).Flags(
-				giu.PlotFlagsNoLegend,
-			).AxisLimits(
-				minXAxis,
-				maxXAxis,
-				yAxisMin,
-				yAxisMax,
-				giu.ConditionAlways,
-			).Plots(
-				giu.PlotLineXY("Mem", memX, memY),
-			).XAxeFlags(
-				giu.PlotAxisFlagsTime,
-			).XTicks(
-				xTicks, false,
-			).YTicks(
-				yTicks, false, 0,
-			).Build()
-		}),
+		memoryHistoryPlot(data, minXAxis, maxXAxis, tunables().TooltipWidth, tunables().TooltipHeight),
 	)
 }
 
 func networkHistoryTooltip(data ContainerData, minXAxis float64, maxXAxis float64) giu.Widget {
 	return giu.Tooltip("Network History").Layout(
 		giu.Label(data.AlternativeName),
-		giu.Custom(func() {
-			var (
-				xTicks                       []giu.PlotTicker = nil
-				yTicks                       []giu.PlotTicker = nil
-				yAxisMin                                      = 0.
-				yAxisMax                                      = 0.
-				netRxX, netRxY                                = make([]float64, 0), make([]float64, 0)
-				netTxX, netTxY                                = make([]float64, 0), make([]float64, 0)
-				netRxMin, netRxMax, netRxAvg float64
-				netTxMin, netTxMax, netTxAvg float64
-			)
-			if !time.Unix(data.LastUpdated, 0).IsZero() && len(data.NetworkRxHistory.Samples) > 0 {
-				netRxX, netRxY = data.NetworkRxHistory.GetXY()
-				netRxMin, netRxMax = data.NetworkRxHistory.GetYMinMax(minXAxis, maxXAxis)
-				netRxAvg = data.NetworkRxHistory.GetYAvg(minXAxis, maxXAxis)
-				netTxX, netTxY = data.NetworkTxHistory.GetXY()
-				netTxMin, netTxMax = data.NetworkTxHistory.GetYMinMax(minXAxis, maxXAxis)
-				netTxAvg = data.NetworkTxHistory.GetYAvg(minXAxis, maxXAxis)
-				netMin := math.Min(netRxMin, netTxMin)
-				netMax := math.Max(netRxMax, netTxMax)
-				xTicks = buildPlotTicker(minXAxis, maxXAxis, 2*time.Minute.Seconds(), func(value float64) string {
-					h, m, _ := time.Unix(int64(value), 0).Clock()
-					return fmt.Sprintf("%02d:%02d", h, m)
-				})
-				yInterval := buildPlotInterval(netMin, netMax, MemoryIntervals)
-				yTicks = buildPlotTicker(netMin, netMax, yInterval, func(value float64) string {
-					return bytesize.New(value).String()
-				})
-				yAxisMin = yTicks[0].Position
-				yAxisMax = yTicks[len(yTicks)-1].Position
-			}
-			giu.Plot(
-				fmt.Sprintf(
-					"RX: avg %s, max %s\nTX: avg %s, max %s",
-					bytesize.New(netRxAvg).String(), bytesize.New(netRxMax).String(),
-					bytesize.New(netTxAvg).String(), bytesize.New(netTxMax).String()),
-			).Size(
-				TooltipWidth, TooltipHeight,
-			).AxisLimits(
-				minXAxis,
-				maxXAxis,
-				yAxisMin,
-				yAxisMax,
-				giu.ConditionAlways,
-			).Plots(
-				giu.PlotLineXY("RX", netRxX, netRxY),
-				giu.PlotLineXY("TX", netTxX, netTxY),
-			).XAxeFlags(
-				giu.PlotAxisFlagsTime,
-			).XTicks(
-				xTicks, false,
-			).YTicks(
-				yTicks, false, 0,
-			).Build()
-		}),
+		networkHistoryPlot(data, minXAxis, maxXAxis, tunables().TooltipWidth, tunables().TooltipHeight),
 	)
 }
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	// WebhookQueueSize is the number of notifications queued per webhook, further notifications are dropped
+	WebhookQueueSize = 100
+
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookConfig configures a webhook notification channel
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	// Template is a text/template rendering the request body from a Notification, defaults to the notification as JSON.
+	// Function "json" encodes a value as JSON, e.g. {"text": {{json .Message}}}
+	Template string `yaml:"template"`
+	// Events are the kinds of notifications sent, all kinds if empty
+	Events []string `yaml:"events"`
+	// Retries is the number of retries of a failed request, the delay starts at RetryBackoff and doubles on every retry
+	Retries      int           `yaml:"retries"`
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// RateLimit is the max number of notifications sent per minute, further notifications are dropped
+	RateLimit int `yaml:"rate_limit"`
+}
+
+// defaultWebhookConfig holds the values of keys missing in the config of a webhook
+var defaultWebhookConfig = WebhookConfig{
+	Method:       http.MethodPost,
+	Retries:      3,
+	RetryBackoff: time.Second,
+	RateLimit:    30,
+}
+
+func (c *WebhookConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain WebhookConfig
+	config := plain(defaultWebhookConfig)
+	if err := node.Decode(&config); err != nil {
+		return err
+	}
+	*c = WebhookConfig(config)
+	return nil
+}
+
+// Validate returns an error describing the first invalid value
+func (c WebhookConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("url must be an absolute http or https URL, got %q", c.URL)
+	}
+	if _, err := parseWebhookTemplate(c.Template); err != nil {
+		return fmt.Errorf("invalid template: %v", err)
+	}
+	for _, event := range c.Events {
+		if !slices.Contains(NotificationKinds, event) {
+			return fmt.Errorf("unknown event %q, expected one of %s", event, strings.Join(NotificationKinds, ", "))
+		}
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("retries must not be negative, got %d", c.Retries)
+	}
+	if c.RetryBackoff <= 0 {
+		return fmt.Errorf("retry_backoff must be positive, got %s", c.RetryBackoff)
+	}
+	if c.RateLimit < 1 {
+		return fmt.Errorf("rate_limit must be at least 1, got %d", c.RateLimit)
+	}
+	return nil
+}
+
+// Equal returns true if both configs have the same values
+func (c WebhookConfig) Equal(other WebhookConfig) bool {
+	return c.URL == other.URL && c.Method == other.Method && maps.Equal(c.Headers, other.Headers) &&
+		c.Template == other.Template && slices.Equal(c.Events, other.Events) &&
+		c.Retries == other.Retries && c.RetryBackoff == other.RetryBackoff && c.RateLimit == other.RateLimit
+}
+
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+	}).Parse(text)
+}
+
+// Webhook sends notifications as HTTP requests to a URL
+type Webhook struct {
+	config   WebhookConfig
+	template *template.Template
+	client   *http.Client
+
+	queue  chan Notification
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sent []time.Time // times of notifications sent within the last minute
+}
+
+var _ NotificationChannel = &Webhook{}
+
+var (
+	webhooks      []*Webhook // webhooks of the config applied last
+	webhooksMutex = sync.Mutex{}
+)
+
+// setWebhookConfigs (re)creates the webhooks of the configs, running webhooks are kept if their config didn't change
+func setWebhookConfigs(configs []WebhookConfig) {
+	webhooksMutex.Lock()
+	defer webhooksMutex.Unlock()
+	previous := webhooks
+	webhooks = nil
+	channels := make([]NotificationChannel, 0, len(configs))
+	for _, config := range configs {
+		if idx := slices.IndexFunc(previous, func(w *Webhook) bool { return w.config.Equal(config) }); idx >= 0 {
+			webhooks = append(webhooks, previous[idx])
+			channels = append(channels, previous[idx])
+			previous = slices.Delete(previous, idx, idx+1)
+			continue
+		}
+		webhook, err := NewWebhook(config)
+		if err != nil {
+			fmt.Printf("Failed to create webhook %s: %v\n", config.URL, err)
+			continue
+		}
+		webhooks = append(webhooks, webhook)
+		channels = append(channels, webhook)
+	}
+	setNotificationChannels("webhook", channels)
+}
+
+// NewWebhook creates a webhook and starts delivering its queued notifications
+func NewWebhook(config WebhookConfig) (*Webhook, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	tmpl, _ := parseWebhookTemplate(config.Template)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Webhook{
+		config:   config,
+		template: tmpl,
+		client:   &http.Client{Timeout: webhookTimeout},
+		queue:    make(chan Notification, WebhookQueueSize),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	go w.deliver()
+	return w, nil
+}
+
+func (w *Webhook) Notify(notification Notification) {
+	if len(w.config.Events) > 0 && !slices.Contains(w.config.Events, notification.Kind) {
+		return
+	}
+	select {
+	case w.queue <- notification:
+	default:
+		fmt.Printf("Dropped %s notification of webhook %s, queue is full\n", notification.Kind, w.config.URL)
+	}
+}
+
+func (w *Webhook) Close() {
+	w.cancel()
+}
+
+// allow returns true if the rate limit permits sending another notification
+func (w *Webhook) allow(now time.Time) bool {
+	w.sent = slices.DeleteFunc(w.sent, func(t time.Time) bool {
+		return now.Sub(t) >= time.Minute
+	})
+	if len(w.sent) >= w.config.RateLimit {
+		return false
+	}
+	w.sent = append(w.sent, now)
+	return true
+}
+
+func (w *Webhook) deliver() {
+	for {
+		select {
+		case notification := <-w.queue:
+			if !w.allow(time.Now()) {
+				fmt.Printf("Dropped %s notification of webhook %s, rate limit of %d per minute exceeded\n", notification.Kind, w.config.URL, w.config.RateLimit)
+				continue
+			}
+			if err := w.send(notification); err != nil {
+				fmt.Printf("Failed to send %s notification to webhook %s: %v\n", notification.Kind, w.config.URL, err)
+			}
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// send posts the notification, failed requests are retried with exponential backoff
+func (w *Webhook) send(notification Notification) error {
+	body, err := w.body(notification)
+	if err != nil {
+		return err
+	}
+	backoff := w.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.request(body)
+		if err == nil || !retry || attempt >= w.config.Retries {
+			return err
+		}
+		fmt.Printf("Retrying webhook %s in %s: %v\n", w.config.URL, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-w.ctx.Done():
+			return w.ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// body renders the request body of the notification
+func (w *Webhook) body(notification Notification) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(notification)
+	}
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, notification); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// request sends a single request, returns whether a failed request should be retried
+func (w *Webhook) request(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(w.ctx, w.config.Method, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "container-hud")
+	for name, value := range w.config.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return w.ctx.Err() == nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return false, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver is a local HTTP listener recording the requests of webhooks
+type webhookReceiver struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	statuses []int // status codes of the next responses, 200 if none is left
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, string(body))
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// Received returns the number of requests received
+func (r *webhookReceiver) Received() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.requests)
+}
+
+// startWebhook creates a webhook of the config until the test ended
+func startWebhook(t *testing.T, config WebhookConfig) *Webhook {
+	t.Helper()
+	webhook, err := NewWebhook(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(webhook.Close)
+	return webhook
+}
+
+func TestWebhookPostsNotifications(t *testing.T) {
+	t.Setenv("HUD_WEBHOOK_TOKEN", "secret")
+	receiver := newWebhookReceiver(t)
+	config := defaultWebhookConfig
+	config.URL = receiver.URL + "/hook"
+	config.Headers = map[string]string{"Authorization": "Bearer ${HUD_WEBHOOK_TOKEN}"}
+	config.Events = []string{NotifyDie}
+	config.Template = `{"text": {{json .Message}}, "exitCode": {{.ExitCode}}}`
+	webhook := startWebhook(t, config)
+
+	webhook.Notify(Notification{Kind: NotifyStart, Message: "web started"})
+	webhook.Notify(Notification{Kind: NotifyDie, Message: "web \"died\"", ExitCode: 3})
+	waitFor(t, "notification to be posted", func() bool {
+		return receiver.Received() == 1
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+	if len(receiver.requests) != 1 {
+		t.Fatalf("expected only the die notification to be posted, got %d requests", len(receiver.requests))
+	}
+	req := receiver.requests[0]
+	if req.Method != http.MethodPost || req.URL.Path != "/hook" {
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+	}
+	if auth := req.Header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("expected expanded authorization header, got %q", auth)
+	}
+	if expected := `{"text": "web \"died\"", "exitCode": 3}`; receiver.bodies[0] != expected {
+		t.Errorf("expected body %s, got %s", expected, receiver.bodies[0])
+	}
+}
+
+func TestWebhookRetriesFailedRequests(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	config := defaultWebhookConfig
+	config.URL = receiver.URL
+	config.RetryBackoff = 10 * time.Millisecond
+	webhook := startWebhook(t, config)
+
+	webhook.Notify(Notification{Kind: NotifyStart, Message: "web started"})
+	waitFor(t, "notification to be posted after retries", func() bool {
+		return receiver.Received() == 3
+	})
+}
+
+func TestWebhookDropsNotificationsBeyondRateLimit(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	config := defaultWebhookConfig
+	config.URL = receiver.URL
+	config.RateLimit = 2
+	webhook := startWebhook(t, config)
+
+	for i := 0; i < 5; i++ {
+		webhook.Notify(Notification{Kind: NotifyStart, Message: "web started"})
+	}
+	waitFor(t, "notifications within rate limit", func() bool {
+		return receiver.Received() == 2
+	})
+	time.Sleep(50 * time.Millisecond)
+	if received := receiver.Received(); received != 2 {
+		t.Errorf("expected 2 notifications within rate limit, got %d", received)
+	}
+}
+
+func TestSetWebhookConfigsKeepsUnchangedWebhooks(t *testing.T) {
+	t.Cleanup(func() {
+		setWebhookConfigs(nil)
+	})
+	config := func(url string) WebhookConfig {
+		config := defaultWebhookConfig
+		config.URL = url
+		return config
+	}
+
+	setWebhookConfigs([]WebhookConfig{config("http://a.example.com"), config("http://b.example.com")})
+	a, b := webhooks[0], webhooks[1]
+
+	changed := config("http://b.example.com")
+	changed.RateLimit = 5
+	setWebhookConfigs([]WebhookConfig{config("http://a.example.com"), changed})
+	if webhooks[0] != a {
+		t.Errorf("expected unchanged webhook to be kept")
+	}
+	if a.ctx.Err() != nil {
+		t.Errorf("expected unchanged webhook to keep delivering")
+	}
+	if webhooks[1] == b || b.ctx.Err() == nil {
+		t.Errorf("expected changed webhook to be replaced and closed")
+	}
+	if channels := notificationChannels["webhook"]; len(channels) != 2 || channels[0] != a {
+		t.Errorf("expected the webhooks to be the notification channels, got %v", channels)
+	}
+}